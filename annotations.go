@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/truszkowski/launchdarkly-flags/launchdarkly"
+)
+
+// Annotations map flag keys, optionally prefixed with "project/", to notes
+// explaining e.g. why a stale flag is kept.
+type Annotations map[string]string
+
+// loadAnnotations reads annotations from a JSON object file, e.g.
+// {"new-checkout": "kill switch, keep", "web/beta-banner": "removal in JIRA-123"}.
+func loadAnnotations(path string) (Annotations, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var annotations Annotations
+	if err := json.Unmarshal(data, &annotations); err != nil {
+		return nil, err
+	}
+
+	return annotations, nil
+}
+
+// Note returns the note for the flag, preferring one given for its project.
+func (a Annotations) Note(f launchdarkly.Flag) (string, bool) {
+	if note, ok := a[f.Project+"/"+f.Key]; ok {
+		return note, true
+	}
+	note, ok := a[f.Key]
+	return note, ok
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+
+	"github.com/truszkowski/launchdarkly-flags/launchdarkly"
+)
+
+// Anonymizer replaces flag keys, member emails and other identifying values
+// with stable tokens, so the same input always maps to the same token across
+// runs.
+//
+// Without a salt the tokens are plain hashes, which hide values but don't
+// protect guessable ones: hashing candidate keys reverses them. A secret
+// salt prevents that while keeping tokens stable across runs using it.
+type Anonymizer struct {
+	Mapping map[string]string
+
+	salt string
+}
+
+func NewAnonymizer(salt string) *Anonymizer {
+	return &Anonymizer{Mapping: map[string]string{}, salt: salt}
+}
+
+func (a *Anonymizer) token(prefix, value string) string {
+	var sum []byte
+	if a.salt == "" {
+		plain := sha256.Sum256([]byte(prefix + ":" + value))
+		sum = plain[:]
+	} else {
+		mac := hmac.New(sha256.New, []byte(a.salt))
+		mac.Write([]byte(prefix + ":" + value))
+		sum = mac.Sum(nil)
+	}
+	token := prefix + "-" + hex.EncodeToString(sum[:6])
+	a.Mapping[token] = value
+	return token
+}
+
+func (a *Anonymizer) email(value string) string {
+	if value == "" || value == "unknown" {
+		return value
+	}
+	return a.token("member", value) + "@example.com"
+}
+
+func (a *Anonymizer) tokens(prefix string, values []string) []string {
+	if values == nil {
+		return nil
+	}
+	tokens := make([]string, len(values))
+	for i, value := range values {
+		tokens[i] = a.token(prefix, value)
+	}
+	return tokens
+}
+
+// Anonymize replaces the keys, project, member emails, tags, custom property
+// values and flag dependencies of every flag, and drops its free text. The
+// "unknown" member is kept as is, it does not disclose anything.
+func (a *Anonymizer) Anonymize(flags []launchdarkly.Flag) {
+	for i := range flags {
+		flags[i].Key = a.token("flag", flags[i].Key)
+		flags[i].Project = a.token("project", flags[i].Project)
+		flags[i].MaintainerEmail = a.email(flags[i].MaintainerEmail)
+		flags[i].CreatedBy = a.email(flags[i].CreatedBy)
+		flags[i].Tags = a.tokens("tag", flags[i].Tags)
+		flags[i].Prerequisites = a.tokens("flag", flags[i].Prerequisites)
+		flags[i].RequiredBy = a.tokens("flag", flags[i].RequiredBy)
+		for key, values := range flags[i].CustomProperties {
+			flags[i].CustomProperties[key] = a.tokens("value", values)
+		}
+		flags[i].Description = ""
+		flags[i].Note = ""
+		flags[i].Site = ""
+	}
+}
+
+// Save writes the token to original value mapping as JSON.
+func (a *Anonymizer) Save(path string) error {
+	data, err := json.MarshalIndent(a.Mapping, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/truszkowski/launchdarkly-flags/launchdarkly"
+)
+
+func TestAnonymize(t *testing.T) {
+	flags := []launchdarkly.Flag{{
+		Key:              "checkout-redesign",
+		Project:          "storefront",
+		Description:      "new checkout for acme corp",
+		MaintainerEmail:  "jane@acme.test",
+		CreatedBy:        "john@acme.test",
+		Tags:             []string{"acme-growth"},
+		Note:             "ask jane",
+		CustomProperties: map[string][]string{"jira": {"ACME-123"}},
+		Prerequisites:    []string{"payments-v2"},
+		RequiredBy:       []string{"checkout-upsell"},
+		Site:             "/acme/storefront/features/checkout-redesign",
+	}}
+	NewAnonymizer("").Anonymize(flags)
+
+	data, err := json.Marshal(struct {
+		Record FlagRecord
+		Flag   launchdarkly.Flag
+	}{newRecord(flags[0], time.Hour, ""), flags[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range []string{"acme", "checkout", "storefront", "jane", "john", "payments"} {
+		if strings.Contains(strings.ToLower(string(data)), value) {
+			t.Errorf("anonymized flag still contains %q: %s", value, data)
+		}
+	}
+	if flags[0].Prerequisites[0] != NewAnonymizer("").token("flag", "payments-v2") {
+		t.Errorf("prerequisite %q is not the token of its flag key", flags[0].Prerequisites[0])
+	}
+}
+
+func TestAnonymizeSalt(t *testing.T) {
+	unsalted := NewAnonymizer("").token("flag", "checkout")
+	salted := NewAnonymizer("secret").token("flag", "checkout")
+	if salted == unsalted {
+		t.Errorf("got the unsalted token %s with a salt", salted)
+	}
+	if again := NewAnonymizer("secret").token("flag", "checkout"); again != salted {
+		t.Errorf("got %s and %s with the same salt, want stable tokens", salted, again)
+	}
+}
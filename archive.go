@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func runArchive(args []string) {
+	var connection connectionFlags
+	var comment string
+	var dryRun bool
+
+	fs := newFlagSet("archive", "<key>...")
+	connection.register(fs)
+	fs.StringVar(&comment, "comment", "archived as stale by launchdarkly-flags", "comment recorded in the audit log")
+	fs.BoolVar(&dryRun, "dry-run", false, "only print what would be archived")
+	parseFlags(fs, args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	client := connection.client()
+	ctx, cancel := connection.context()
+	defer cancel()
+
+	for _, key := range fs.Args() {
+		if dryRun {
+			fmt.Printf("would archive %s/%s\n", connection.project, key)
+			continue
+		}
+		if err := client.ArchiveFlag(ctx, connection.project, key, comment); err != nil {
+			fail(fmt.Errorf("failed to archive %s: %w", key, err))
+		}
+		fmt.Printf("archived %s/%s\n", connection.project, key)
+	}
+}
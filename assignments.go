@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/truszkowski/launchdarkly-flags/launchdarkly"
+)
+
+// saveAssignments writes the flags as csv grouped by maintainer, oldest flag
+// first, with an empty line between maintainers, so the sheet is easy to
+// split into per-person cleanup lists.
+func saveAssignments(path string, flags []launchdarkly.Flag, link func(launchdarkly.Flag) string) error {
+	sorted := append([]launchdarkly.Flag(nil), flags...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].MaintainerEmail != sorted[j].MaintainerEmail {
+			return sorted[i].MaintainerEmail < sorted[j].MaintainerEmail
+		}
+		return sorted[i].CreationDate.Before(sorted[j].CreationDate)
+	})
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"maintainer", "key", "age_days", "link"})
+	for i, item := range sorted {
+		if i > 0 && item.MaintainerEmail != sorted[i-1].MaintainerEmail {
+			w.Write([]string{})
+		}
+		w.Write([]string{item.MaintainerEmail, item.Key, fmt.Sprintf("%.0f", days(item.Age())), link(item)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
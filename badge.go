@@ -0,0 +1,32 @@
+package main
+
+import "strconv"
+
+// Badge is the shields.io endpoint badge schema, see
+// https://shields.io/badges/endpoint-badge.
+type Badge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// staleBadge shows the number of stale flags, greener the fewer there are.
+func staleBadge(count int) Badge {
+	color := "red"
+	switch {
+	case count == 0:
+		color = "brightgreen"
+	case count < 10:
+		color = "yellow"
+	case count < 50:
+		color = "orange"
+	}
+
+	return Badge{
+		SchemaVersion: 1,
+		Label:         "stale flags",
+		Message:       strconv.Itoa(count),
+		Color:         color,
+	}
+}
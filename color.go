@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// ANSI codes used to color the text table. They all have the same length,
+// so prefixing every line with one keeps tabwriter's columns aligned.
+const (
+	colorBold    = "\x1b[01m"
+	colorRed     = "\x1b[31m"
+	colorYellow  = "\x1b[33m"
+	colorDefault = "\x1b[39m"
+	colorReset   = "\x1b[00m"
+)
+
+// useColor resolves the -color option: always and never are explicit, auto
+// colors only a terminal and honors the NO_COLOR convention, see
+// https://no-color.org.
+func useColor(mode string) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		if os.Getenv("NO_COLOR") != "" {
+			return false, nil
+		}
+		info, err := os.Stdout.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0, nil
+	default:
+		return false, fmt.Errorf("unknown color mode %q, use auto, always or never", mode)
+	}
+}
@@ -0,0 +1,414 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/truszkowski/launchdarkly-flags/launchdarkly"
+)
+
+// commands are the subcommands, the first argument picks one. Without it,
+// or when it is a flag, report runs, or explain with -key or -compare, as
+// before subcommands existed.
+var commands = map[string]struct {
+	run         func(args []string)
+	description string
+}{
+	"report":  {runReport, "report stale flags (default)"},
+	"list":    {runList, "print keys of stale flags, one per line"},
+	"summary": {runSummary, "print only the summary of stale flags"},
+	"explain": {runExplain, "show details of a single flag"},
+	"archive": {runArchive, "archive flags by key"},
+}
+
+func main() {
+	launchdarkly.Warn = warnf
+
+	name, args := "report", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	} else if explainArgs, ok := legacyExplainArgs(args); ok {
+		name, args = "explain", explainArgs
+	}
+
+	command, ok := commands[name]
+	if !ok {
+		printCommands()
+		usage("unknown command %q", name)
+	}
+	command.run(args)
+	finishWarnings()
+}
+
+// legacyExplainArgs turns the -key and -compare options of the flag-only
+// invocation into the arguments of the explain command, which replaced them
+// and is what they keep working as, hidden from the report usage. It is
+// false when neither option is given.
+func legacyExplainArgs(args []string) ([]string, bool) {
+	var key string
+	var compare bool
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		if !strings.HasPrefix(args[i], "-") || (name != "key" && name != "compare") {
+			rest = append(rest, args[i])
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		key, compare = value, compare || name == "compare"
+	}
+	if key == "" {
+		return nil, false
+	}
+
+	if compare {
+		rest = append(rest, "-compare")
+	}
+	return append(rest, key), true
+}
+
+func printCommands() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(os.Stderr, "usage: %s <command> [flags]\n\ncommands:\n", os.Args[0])
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", name, commands[name].description)
+	}
+	fmt.Fprintln(os.Stderr)
+}
+
+// newFlagSet returns a flag set for the command with a usage header naming
+// it.
+func newFlagSet(name, arguments string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s %s [flags] %s\n", os.Args[0], name, arguments)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args and then fills flags not given on the command line
+// from LDFLAGS_ environment variables.
+func parseFlags(fs *flag.FlagSet, args []string) {
+	fs.Parse(args)
+	if err := flagsFromEnv(fs, "LDFLAGS_"); err != nil {
+		usage("%v", err)
+	}
+}
+
+// connectionFlags are the flags shared by all commands: where to connect,
+// how to authorize and how to behave on the wire.
+type connectionFlags struct {
+	project, env, token string
+	host, appHost       string
+	linkTab             string
+	apiVersion          string
+	headers             headerFlag
+	timeout             time.Duration
+	requestTimeout      time.Duration
+	retries             int
+	verbose             bool
+	progress            bool
+	eventsFile          string
+	respectRateLimit    int
+	rps                 float64
+	maxResponseBytes    int64
+	statusEnvs          string
+	statusBatchSize     int
+	membersCache        string
+	membersCacheTTL     time.Duration
+	caCert              string
+	insecureSkipVerify  bool
+	dumpRaw             string
+	fromFile            string
+}
+
+func (c *connectionFlags) register(fs *flag.FlagSet) {
+	c.headers = headerFlag{}
+
+	fs.StringVar(&c.project, "project", "default", "project to check")
+	fs.StringVar(&c.env, "env", "production", "environment to check")
+	fs.StringVar(&c.token, "token", "LAUNCH_DARKLY_API_TOKEN", "env-var name with api token to authorize")
+	fs.StringVar(&c.host, "host", launchdarkly.DefaultHost, "API host")
+	fs.StringVar(&c.apiVersion, "api-version", "", "LD-API-Version to request flags with, the token's default if empty")
+	fs.StringVar(&c.appHost, "app-host", "", "web app host for flag links, -host by default")
+	fs.StringVar(&c.linkTab, "link-tab", "targeting", "tab of the flag in -env that links open: overview, targeting or settings")
+	fs.Var(c.headers, "header", "extra request header as key=value, may be repeated")
+	fs.DurationVar(&c.timeout, "timeout", 5*time.Minute, "timeout of the whole run")
+	fs.DurationVar(&c.requestTimeout, "request-timeout", time.Minute, "timeout of every single request")
+	fs.IntVar(&c.retries, "retries", 3, "how many times to retry rate-limited, failed or transiently broken requests")
+	fs.BoolVar(&warningsAsErrors, "warnings-as-errors", false, "exit with code 7 if there were any warnings, e.g. of missing or partial data")
+	fs.BoolVar(&c.verbose, "verbose", false, "log every request and the remaining rate limit to stderr")
+	fs.BoolVar(&c.progress, "progress", false, "print the number of fetched flags after every page to stderr")
+	fs.StringVar(&c.eventsFile, "events-file", "", "file to write fetching progress events to as json lines, e.g. /dev/fd/3")
+	fs.IntVar(&c.respectRateLimit, "respect-ratelimit", 0, "wait for the rate limit reset once the remaining budget drops to this, 0 to never wait")
+	fs.Float64Var(&c.rps, "rps", 0, "send at most this many requests per second, 0 for no limit")
+	fs.Int64Var(&c.maxResponseBytes, "max-response-bytes", 64<<20, "fail on API responses larger than this, 0 for no limit")
+	fs.StringVar(&c.statusEnvs, "status-envs", "", "comma-separated extra environments to show last requested for")
+	fs.IntVar(&c.statusBatchSize, "status-batch-size", 0, "max flag keys per status query, 0 for a whole page at once")
+	fs.StringVar(&c.caCert, "ca-cert", "", "pem file with extra CA certificates to trust, e.g. of a TLS-intercepting proxy")
+	fs.BoolVar(&c.insecureSkipVerify, "insecure-skip-verify", false, "INSECURE: don't verify TLS certificates at all")
+	fs.StringVar(&c.dumpRaw, "dump-raw", "", "file, or existing directory for a file per response, to save the raw API responses to, for -from-file")
+	fs.StringVar(&c.fromFile, "from-file", "", "answer API requests from a -dump-raw file or directory instead of the network")
+	fs.StringVar(&c.membersCache, "members-cache", "", "file to cache the member list in")
+	fs.DurationVar(&c.membersCacheTTL, "members-cache-ttl", 24*time.Hour, "how long the -members-cache file stays valid")
+}
+
+func (c *connectionFlags) shownEnvs() []string {
+	return splitList(c.statusEnvs)
+}
+
+func (c *connectionFlags) client() *launchdarkly.Client {
+	switch c.linkTab {
+	case "overview", "targeting", "settings":
+	default:
+		usage("invalid -link-tab %q, expected overview, targeting or settings", c.linkTab)
+	}
+
+	client := launchdarkly.NewClient(os.Getenv(c.token), launchdarkly.WithHost(strings.TrimSuffix(c.host, "/")), launchdarkly.WithHeaders(http.Header(c.headers)), launchdarkly.WithAPIVersion(c.apiVersion))
+	client.StatusEnvs = c.shownEnvs()
+	client.RequestTimeout = c.requestTimeout
+	client.StatusBatchSize = c.statusBatchSize
+	client.Retries = c.retries
+	client.RateLimitThreshold = c.respectRateLimit
+	if c.rps > 0 {
+		client.Limiter = rate.NewLimiter(rate.Limit(c.rps), 1)
+	}
+	client.MaxResponseBytes = c.maxResponseBytes
+	client.MembersCache = c.membersCache
+	client.MembersCacheTTL = c.membersCacheTTL
+	if c.verbose {
+		client.Verbose = os.Stderr
+	}
+	var events []func(launchdarkly.Event)
+	if c.progress {
+		events = append(events, progressEvents(os.Stderr))
+	}
+	if c.eventsFile != "" {
+		file, err := os.Create(c.eventsFile)
+		if err != nil {
+			fail(fmt.Errorf("failed to open events file: %w", err))
+		}
+		events = append(events, jsonEvents(file))
+	}
+	if len(events) > 0 {
+		client.Events = allEvents(events...)
+	}
+
+	if c.caCert != "" || c.insecureSkipVerify {
+		config, err := c.tlsConfig()
+		if err != nil {
+			usage("invalid -ca-cert: %v", err)
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = config
+		client.Client.Transport = transport
+	}
+
+	switch {
+	case c.dumpRaw != "" && c.fromFile != "":
+		usage("-dump-raw cannot be used with -from-file")
+	case c.dumpRaw != "":
+		next := client.Client.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		client.Client.Transport = &launchdarkly.RecordTransport{Next: next, Dump: launchdarkly.NewDump(c.dumpRaw)}
+	case c.fromFile != "":
+		dump, err := launchdarkly.LoadDump(c.fromFile)
+		if err != nil {
+			usage("invalid -from-file: %v", err)
+		}
+		client.Client.Transport = &launchdarkly.ReplayTransport{Dump: dump}
+	}
+	return client
+}
+
+// tlsConfig trusts the -ca-cert certificates besides the system ones, or
+// nothing at all with -insecure-skip-verify.
+func (c *connectionFlags) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{}
+
+	if c.insecureSkipVerify {
+		warnf("-insecure-skip-verify is set, TLS certificates are not verified")
+		config.InsecureSkipVerify = true
+	}
+
+	if c.caCert != "" {
+		pem, err := os.ReadFile(c.caCert)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", c.caCert)
+		}
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
+
+// linkHost is the host flag links point to.
+func (c *connectionFlags) linkHost() string {
+	if c.appHost != "" {
+		return strings.TrimSuffix(c.appHost, "/")
+	}
+	return strings.TrimSuffix(c.host, "/")
+}
+
+// link returns the link to the -link-tab of the flag in -env.
+func (c *connectionFlags) link(f launchdarkly.Flag) string {
+	return f.Link(c.linkHost(), c.env, c.linkTab)
+}
+
+func (c *connectionFlags) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.timeout)
+}
+
+func runList(args []string) {
+	var connection connectionFlags
+	var selection selectionFlags
+
+	fs := newFlagSet("list", "")
+	connection.register(fs)
+	selection.register(fs)
+	parseFlags(fs, args)
+	selection.parse(&connection)
+
+	client := connection.client()
+	ctx, cancel := connection.context()
+	defer cancel()
+
+	flags := selection.collect(ctx, client, &connection)
+	if selection.explain {
+		selection.explainFilter(os.Stdout, flags)
+		return
+	}
+	for _, item := range flags {
+		fmt.Println(item.Key)
+	}
+}
+
+func runSummary(args []string) {
+	var connection connectionFlags
+	var selection selectionFlags
+	var ageHistogram bool
+
+	fs := newFlagSet("summary", "")
+	connection.register(fs)
+	selection.register(fs)
+	fs.BoolVar(&ageHistogram, "age-histogram", false, "print a histogram of stale flags by creation age as well")
+	parseFlags(fs, args)
+	selection.parse(&connection)
+
+	client := connection.client()
+	ctx, cancel := connection.context()
+	defer cancel()
+
+	flags := selection.collect(ctx, client, &connection)
+	if selection.explain {
+		selection.explainFilter(os.Stdout, flags)
+		return
+	}
+	summary := summarize(flags, selection.statusThreshold())
+	summary.Requests = client.Stats()
+	summary.Print(os.Stdout)
+	if ageHistogram {
+		printAgeHistogram(os.Stdout, flags)
+	}
+}
+
+// headerFlag collects repeated -header key=value options.
+type headerFlag http.Header
+
+func (h headerFlag) String() string {
+	var pairs []string
+	for key, values := range h {
+		for _, value := range values {
+			pairs = append(pairs, key+"="+value)
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (h headerFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("header %q is not in key=value form", value)
+	}
+	http.Header(h).Add(strings.TrimSpace(key), val)
+	return nil
+}
+
+// listFlag collects the values of a flag given several times.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// flagsFromEnv sets every flag not given on the command line from an
+// environment variable named prefix followed by the flag name in upper case
+// with dashes replaced by underscores, e.g. LDFLAGS_STATUS_ENVS.
+func flagsFromEnv(flags *flag.FlagSet, prefix string) error {
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	var err error
+	flags.VisitAll(func(f *flag.Flag) {
+		if set[f.Name] || err != nil {
+			return
+		}
+		name := prefix + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		if value, ok := os.LookupEnv(name); ok {
+			if setErr := flags.Set(f.Name, value); setErr != nil {
+				err = fmt.Errorf("invalid %s: %w", name, setErr)
+			}
+		}
+	})
+	return err
+}
+
+// splitList splits a comma-separated option value, ignoring empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
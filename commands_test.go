@@ -0,0 +1,26 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLegacyExplainArgs(t *testing.T) {
+	for _, test := range []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"-key", "a", "-env", "staging"}, []string{"-env", "staging", "a"}},
+		{[]string{"-env=staging", "--key=a"}, []string{"-env=staging", "a"}},
+		{[]string{"-compare", "a", "-status-envs", "qa,staging"}, []string{"-status-envs", "qa,staging", "-compare", "a"}},
+	} {
+		got, ok := legacyExplainArgs(test.args)
+		if !ok || !slices.Equal(got, test.want) {
+			t.Errorf("legacyExplainArgs(%q) = %q, %t, want %q", test.args, got, ok, test.want)
+		}
+	}
+
+	if got, ok := legacyExplainArgs([]string{"-env", "staging", "-format", "json"}); ok {
+		t.Errorf("got explain arguments %q of a report invocation", got)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+// Diff is the delta between a previous report and the current one.
+type Diff struct {
+	New       []FlagRecord `json:"new"`
+	Removed   []FlagRecord `json:"removed"`
+	Remaining []FlagRecord `json:"remaining"`
+}
+
+// diffRecords compares reports by project and flag key: flags only in current are newly
+// stale, flags only in previous were cleaned up, the rest remain. Current
+// records keep their order, removed ones keep the previous report's order.
+func diffRecords(previous, current []FlagRecord) Diff {
+	diff := Diff{
+		New:       []FlagRecord{},
+		Removed:   []FlagRecord{},
+		Remaining: []FlagRecord{},
+	}
+
+	seen := map[string]bool{}
+	for _, record := range previous {
+		seen[record.Project+"/"+record.Key] = true
+	}
+
+	reported := map[string]bool{}
+	for _, record := range current {
+		reported[record.Project+"/"+record.Key] = true
+		if seen[record.Project+"/"+record.Key] {
+			diff.Remaining = append(diff.Remaining, record)
+		} else {
+			diff.New = append(diff.New, record)
+		}
+	}
+
+	for _, record := range previous {
+		if !reported[record.Project+"/"+record.Key] {
+			diff.Removed = append(diff.Removed, record)
+		}
+	}
+
+	return diff
+}
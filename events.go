@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/truszkowski/launchdarkly-flags/launchdarkly"
+)
+
+// progressEvents prints fetched pages for humans.
+func progressEvents(w io.Writer) func(launchdarkly.Event) {
+	return func(event launchdarkly.Event) {
+		switch {
+		case event.Type != "page":
+		case event.Total > 0:
+			fmt.Fprintf(w, "%s: %d / %d flags (%d%%)\n", event.Project, event.Fetched, event.Total, event.Fetched*100/event.Total)
+		default:
+			fmt.Fprintf(w, "%s: %d flags\n", event.Project, event.Fetched)
+		}
+	}
+}
+
+// jsonEvents writes every event as a json line.
+func jsonEvents(w io.Writer) func(launchdarkly.Event) {
+	encoder := json.NewEncoder(w)
+	return func(event launchdarkly.Event) {
+		encoder.Encode(event)
+	}
+}
+
+// allEvents passes events to every one of handlers in turn, one event at a
+// time, as projects may be fetched in parallel.
+func allEvents(handlers ...func(launchdarkly.Event)) func(launchdarkly.Event) {
+	var mu sync.Mutex
+	return func(event launchdarkly.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, handler := range handlers {
+			handler(event)
+		}
+	}
+}
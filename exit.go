@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/truszkowski/launchdarkly-flags/launchdarkly"
+)
+
+// Exit codes, so scripts can tell failure classes apart.
+const (
+	exitError   = 1
+	exitUsage   = 2
+	exitAuth    = 3
+	exitNetwork = 4
+	exitAPI     = 5
+
+	// exitUnowned is returned by -require-maintainer when stale flags have
+	// no maintainer.
+	exitUnowned = 6
+
+	// exitWarnings is returned by -warnings-as-errors when there were
+	// warnings.
+	exitWarnings = 7
+)
+
+// fail prints err to stderr and exits with the code of its class.
+func fail(err error) {
+	printWarnings()
+	fmt.Fprintln(os.Stderr, "error:", err)
+
+	var authErr *launchdarkly.AuthError
+	var rateLimitErr *launchdarkly.RateLimitError
+	var notFoundErr *launchdarkly.NotFoundError
+	if errors.As(err, &authErr) {
+		fmt.Fprintln(os.Stderr, "hint: check that the api token is set and has access to the project")
+	}
+	if errors.As(err, &rateLimitErr) && rateLimitErr.RetryAfter > 0 {
+		fmt.Fprintf(os.Stderr, "hint: rate limited, retry in %s\n", rateLimitErr.RetryAfter.Round(1e9))
+	}
+	if errors.As(err, &notFoundErr) {
+		fmt.Fprintln(os.Stderr, "hint: check the project, environment and flag keys")
+	}
+
+	os.Exit(exitCode(err))
+}
+
+// usage reports invalid command line options, after the warnings so far.
+func usage(format string, args ...interface{}) {
+	printWarnings()
+	fmt.Fprintf(os.Stderr, "error: "+format+"\n", args...)
+	os.Exit(exitUsage)
+}
+
+func exitCode(err error) int {
+	var authErr *launchdarkly.AuthError
+	var apiErr *launchdarkly.APIError
+	var decodeErr *launchdarkly.DecodeError
+	var notRecordedErr *launchdarkly.NotRecordedError
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &notRecordedErr):
+		return exitError
+	case errors.As(err, &authErr):
+		return exitAuth
+	case errors.As(err, &apiErr), errors.As(err, &decodeErr):
+		return exitAPI
+	case errors.As(err, &netErr), errors.Is(err, context.DeadlineExceeded):
+		return exitNetwork
+	default:
+		return exitError
+	}
+}
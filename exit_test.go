@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestUsagePrintsWarnings(t *testing.T) {
+	if os.Getenv("TEST_USAGE_EXIT") != "" {
+		warnf("-with-permanent is deprecated")
+		usage("invalid -type %q", "some")
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestUsagePrintsWarnings$")
+	cmd.Env = append(os.Environ(), "TEST_USAGE_EXIT=1")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != exitUsage {
+		t.Fatalf("got %v, want exit code %d", err, exitUsage)
+	}
+	if want := "warning: -with-permanent is deprecated\nerror: invalid -type \"some\"\n"; stderr.String() != want {
+		t.Errorf("got stderr %q, want %q", stderr.String(), want)
+	}
+}
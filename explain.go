@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func runExplain(args []string) {
+	var connection connectionFlags
+	var threshold time.Duration
+	var compare bool
+
+	fs := newFlagSet("explain", "<key>")
+	connection.register(fs)
+	fs.DurationVar(&threshold, "threshold", defaultThreshold, thresholdUsage)
+	fs.BoolVar(&compare, "compare", false, "show targeting across environments (-status-envs or all) instead")
+	parseFlags(fs, args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	key := fs.Arg(0)
+	threshold = statusThreshold(threshold)
+
+	project, env := connection.project, connection.env
+	shownEnvs := connection.shownEnvs()
+
+	client := connection.client()
+	ctx, cancel := connection.context()
+	defer cancel()
+
+	if compare {
+		configs, err := client.CompareFlag(ctx, project, key, shownEnvs)
+		if err != nil {
+			fail(fmt.Errorf("failed to compare flag: %w", err))
+		}
+
+		tb := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+		fmt.Fprintln(tb, "ENV\tON\tOFF VARIATION\tFALLTHROUGH\tTARGETS\tRULES\tROLLOUT")
+		for _, config := range configs {
+			fmt.Fprintf(tb, "%s\t%t\t%s\t%s\t%d\t%d\t%s\n", config.Env, config.On, config.OffVariation, config.Fallthrough, config.Targets, config.Rules, config.Rollout)
+		}
+		tb.Flush()
+		return
+	}
+
+	item, err := client.GetFlag(ctx, project, env, key)
+	if err != nil {
+		fail(fmt.Errorf("failed to get flag: %w", err))
+	}
+
+	tb := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(tb, "KEY\t%s\n", item.Key)
+	fmt.Fprintf(tb, "PROJECT\t%s\n", item.Project)
+	fmt.Fprintf(tb, "MAINTAINER\t%s\n", item.MaintainerEmail)
+	fmt.Fprintf(tb, "CREATION DATE\t%s\n", item.CreationDateAgo())
+	fmt.Fprintf(tb, "LAST MODIFIED\t%s\n", item.LastModifiedAgo())
+	fmt.Fprintf(tb, "LAST REQUESTED\t%s\n", item.LastRequestedAgo())
+	for _, shownEnv := range shownEnvs {
+		fmt.Fprintf(tb, "LAST REQUESTED (%s)\t%s\n", shownEnv, item.LastRequestedAgoIn(shownEnv))
+	}
+	fmt.Fprintf(tb, "STATUS\t%s\n", item.GetStatus(threshold))
+	fmt.Fprintf(tb, "TEMPORARY\t%s\n", item.GetTemporary())
+	fmt.Fprintf(tb, "VERSION\t%d\n", item.Version)
+	fmt.Fprintf(tb, "ROLLOUT\t%s\n", item.Rollout)
+	if item.Lifecycle != "" {
+		fmt.Fprintf(tb, "LIFECYCLE\t%s\n", item.Lifecycle)
+	}
+	if len(item.Tags) > 0 {
+		fmt.Fprintf(tb, "TAGS\t%s\n", strings.Join(item.Tags, ", "))
+	}
+	if len(item.Prerequisites) > 0 {
+		fmt.Fprintf(tb, "PREREQUISITES\t%s\n", strings.Join(item.Prerequisites, ", "))
+	}
+	fmt.Fprintf(tb, "LINK\t%s\n", connection.link(item))
+	tb.Flush()
+}
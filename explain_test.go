@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// captureStdout returns what run writes to stdout.
+func captureStdout(t *testing.T, run func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- string(data)
+	}()
+	run()
+	w.Close()
+	return <-out
+}
+
+func TestExplainThresholdZero(t *testing.T) {
+	requested := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			fmt.Fprintf(w, `{"items":[{"key":"a","environments":{"production":{"lastRequested":%q}}}]}`, requested)
+			return
+		}
+		fmt.Fprintf(w, `{"key":"a","creationDate":%d,"environments":{"production":{"lastModified":%d}}}`, millisAgo(time.Hour), millisAgo(time.Hour))
+	}))
+	defer server.Close()
+
+	out := captureStdout(t, func() {
+		runExplain([]string{"-host", server.URL, "-threshold", "0", "a"})
+	})
+	if !regexp.MustCompile(`(?m)^STATUS +inuse$`).MatchString(out) {
+		t.Errorf("got %q, want a flag requested a day ago in use with -threshold 0", out)
+	}
+}
@@ -1,3 +1,5 @@
 module github.com/truszkowski/launchdarkly-flags
 
 go 1.22.1
+
+require golang.org/x/time v0.8.0
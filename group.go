@@ -0,0 +1,74 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/truszkowski/launchdarkly-flags/launchdarkly"
+)
+
+// groupByMaintainer reorders flags so that every maintainer's flags are
+// together, keeping their order within a group. Groups are ordered by name,
+// by the number of flags (count) or by their oldest flag (oldest).
+func groupByMaintainer(flags []launchdarkly.Flag, order string) {
+	type group struct {
+		name   string
+		count  int
+		oldest launchdarkly.Flag
+	}
+
+	groups := map[string]*group{}
+	for _, item := range flags {
+		g, ok := groups[item.MaintainerEmail]
+		if !ok {
+			g = &group{name: item.MaintainerEmail, oldest: item}
+			groups[item.MaintainerEmail] = g
+		}
+		g.count++
+		if item.CreationDate.Before(g.oldest.CreationDate) {
+			g.oldest = item
+		}
+	}
+
+	sorted := make([]*group, 0, len(groups))
+	for _, g := range groups {
+		sorted = append(sorted, g)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		switch {
+		case order == "count" && sorted[i].count != sorted[j].count:
+			return sorted[i].count > sorted[j].count
+		case order == "oldest" && !sorted[i].oldest.CreationDate.Equal(sorted[j].oldest.CreationDate):
+			return sorted[i].oldest.CreationDate.Before(sorted[j].oldest.CreationDate)
+		}
+		return sorted[i].name < sorted[j].name
+	})
+
+	rank := map[string]int{}
+	for i, g := range sorted {
+		rank[g.name] = i
+	}
+	sort.SliceStable(flags, func(i, j int) bool {
+		return rank[flags[i].MaintainerEmail] < rank[flags[j].MaintainerEmail]
+	})
+}
+
+// projectSections returns the indices of flags by project, projects ordered
+// by key and flags kept in their order within a project.
+func projectSections(flags []launchdarkly.Flag) [][]int {
+	indices := map[string][]int{}
+	for i, item := range flags {
+		indices[item.Project] = append(indices[item.Project], i)
+	}
+
+	projects := make([]string, 0, len(indices))
+	for project := range indices {
+		projects = append(projects, project)
+	}
+	sort.Strings(projects)
+
+	sections := make([][]int, 0, len(projects))
+	for _, project := range projects {
+		sections = append(sections, indices[project])
+	}
+	return sections
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/truszkowski/launchdarkly-flags/launchdarkly"
+)
+
+var influxTagEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+
+// influxLine renders a flag as an InfluxDB line protocol point of the
+// ld_flags measurement, without inactive_days when the status is unknown.
+func influxLine(env string, f launchdarkly.Flag, threshold time.Duration, now time.Time) string {
+	fields := fmt.Sprintf("age_days=%.2f", days(f.Age()))
+	if !f.StatusUnknown {
+		fields += fmt.Sprintf(",inactive_days=%.2f", days(f.InactiveFor()))
+	}
+	return fmt.Sprintf("ld_flags,project=%s,env=%s,key=%s,maintainer=%s,temporary=%t,status=%s %s %d",
+		influxTagEscaper.Replace(f.Project),
+		influxTagEscaper.Replace(env),
+		influxTagEscaper.Replace(f.Key),
+		influxTagEscaper.Replace(f.MaintainerEmail),
+		f.Temporary,
+		f.GetStatus(threshold),
+		fields,
+		now.UnixNano(),
+	)
+}
+
+func days(d time.Duration) float64 {
+	return float64(d) / float64(24*time.Hour)
+}
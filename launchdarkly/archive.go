@@ -0,0 +1,56 @@
+package launchdarkly
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+func (cli *Client) patch(ctx context.Context, url string, in, out interface{}) error {
+	ctx, cancel := cli.requestContext(ctx)
+	defer cancel()
+
+	inBuffer := bytes.NewBuffer([]byte{})
+	if err := json.NewEncoder(inBuffer).Encode(in); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PATCH", cli.host()+url, inBuffer)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", cli.ApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	cli.setHeaders(req)
+	resp, err := cli.do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+
+	if err := json.NewDecoder(cli.body(resp)).Decode(out); err != nil {
+		return &DecodeError{URL: req.URL.String(), Err: err}
+	}
+
+	return nil
+}
+
+// ArchiveFlag archives the flag in all environments of the project. The
+// comment is recorded in the audit log.
+func (cli *Client) ArchiveFlag(ctx context.Context, project, key, comment string) error {
+	var flagResponse FlagItem
+	return cli.patch(ctx, flagUrl(project, key, nil), map[string]interface{}{
+		"comment": comment,
+		"patch": []map[string]interface{}{
+			{"op": "replace", "path": "/archived", "value": true},
+		},
+	}, &flagResponse)
+}
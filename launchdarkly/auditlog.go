@@ -0,0 +1,45 @@
+package launchdarkly
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type AuditLogResponse struct {
+	Items []struct {
+		Date   int64 `json:"date"`
+		Member struct {
+			Email string `json:"email"`
+		} `json:"member"`
+		Accesses []struct {
+			Action string `json:"action"`
+		} `json:"accesses"`
+	} `json:"items"`
+}
+
+func auditLogUrl(project, key string, before time.Time) string {
+	spec := "proj/" + project + ":env/*:flag/" + key
+	return "/api/v2/auditlog?limit=20&spec=" + url.QueryEscape(spec) + "&before=" + strconv.FormatInt(before.UnixMilli(), 10)
+}
+
+// GetCreatedBy looks up in the audit log who created the flag. It returns
+// an empty string when the audit log has no creation entry, e.g. because it
+// has already expired.
+func (cli *Client) GetCreatedBy(ctx context.Context, project string, f Flag) (string, error) {
+	var auditLogResponse AuditLogResponse
+	if err := cli.get(ctx, auditLogUrl(project, f.Key, f.CreationDate.Add(time.Minute)), &auditLogResponse); err != nil {
+		return "", err
+	}
+
+	for _, item := range auditLogResponse.Items {
+		for _, access := range item.Accesses {
+			if access.Action == "createFlag" {
+				return item.Member.Email, nil
+			}
+		}
+	}
+
+	return "", nil
+}
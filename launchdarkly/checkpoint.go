@@ -0,0 +1,62 @@
+package launchdarkly
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Checkpoint records the progress of a paginated GetFlags run, so a failed
+// run can be resumed from the last successfully fetched page.
+type Checkpoint struct {
+	Project string `json:"project"`
+	Env     string `json:"env"`
+	Next    string `json:"next"`
+	Flags   []Flag `json:"flags"`
+}
+
+func loadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+
+	return &checkpoint, nil
+}
+
+// saveCheckpoint writes the checkpoint through a temporary file, so that an
+// interrupted write never leaves a truncated checkpoint behind.
+func saveCheckpoint(path string, checkpoint *Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,1186 @@
+// Package launchdarkly is a client for the parts of the LaunchDarkly REST
+// API needed to find stale feature flags: listing flags with their
+// statuses, members, projects and the audit log.
+package launchdarkly
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+type Flag struct {
+	Key             string
+	Description     string
+	Project         string
+	MaintainerEmail string
+	CreationDate    time.Time
+	LastModified    time.Time
+	LastRequested   time.Time
+	Temporary       bool
+	Tags            []string
+	Version         int
+	Orphaned        bool
+	Rollout         string
+	CreatedBy       string
+	Note            string
+
+	// CustomProperties are the values of custom properties by their key
+	// and by their name.
+	CustomProperties map[string][]string
+
+	// Watched is set for flags reported because of the watchlist.
+	Watched bool
+
+	// Constant is set when the flag serves a single variation to everyone
+	// in every listed environment, all of them with Client.AllEnvs.
+	Constant bool
+
+	// StatusUnknown is set when the flag status query didn't return the flag
+	// in the checked environment, so LastRequested is unknown rather than
+	// never.
+	StatusUnknown bool
+
+	// RequestsUnavailable is set when the flag status query isn't
+	// available on the plan at all, StatusUnknown is set as well.
+	RequestsUnavailable bool
+
+	// AsOf is the reference time ages are computed relative to, now when
+	// zero.
+	AsOf time.Time
+
+	// Misconfigured is set when the listing has no data of the flag in the
+	// checked environment, e.g. for a flag still being created, so all its
+	// timestamps in it are zero.
+	Misconfigured bool
+
+	// Lifecycle is the flag lifecycle stage set by LaunchDarkly, e.g.
+	// "ready-for-code-removal", empty where the API doesn't provide it.
+	Lifecycle string
+
+	// Site is the link to the flag in the web app as returned by the API,
+	// empty if the API didn't return one.
+	Site string
+
+	// Prerequisites are keys of flags this flag depends on in any of the
+	// fetched environments, RequiredBy are keys of flags depending on it.
+	Prerequisites []string
+	RequiredBy    []string
+
+	// Environments holds per-environment timestamps for every environment
+	// fetched, including the checked one.
+	Environments map[string]EnvironmentStatus
+}
+
+type EnvironmentStatus struct {
+	LastModified  time.Time
+	LastRequested time.Time
+
+	// StatusUnknown is like Flag.StatusUnknown, for this environment.
+	StatusUnknown bool
+}
+
+// LastModifiedMoreThanIn is like LastModifiedMoreThan, but requires the flag
+// to be unmodified for longer than value in every one of envs.
+func (f Flag) LastModifiedMoreThanIn(envs []string, value time.Duration) bool {
+	for _, env := range envs {
+		lastModified := f.Environments[env].LastModified
+		if !lastModified.IsZero() && f.Since(lastModified) <= value {
+			return false
+		}
+	}
+	return true
+}
+
+func (f Flag) CreationDateMoreThan(value time.Duration) bool {
+	return f.CreationDate.IsZero() || f.Since(f.CreationDate) > value
+}
+
+func (f Flag) LastModifiedMoreThan(value time.Duration) bool {
+	return f.LastModified.IsZero() || f.Since(f.LastModified) > value
+}
+
+func (f Flag) LastRequestedMoreThan(value time.Duration) bool {
+	return f.LastRequested.IsZero() || f.Since(f.LastRequested) > value
+}
+
+func (f Flag) CreationDateAgo() string {
+	if f.CreationDate.IsZero() {
+		return "never"
+	}
+	return f.Ago(f.Since(f.CreationDate))
+}
+
+func (f Flag) LastModifiedAgo() string {
+	if f.LastModified.IsZero() {
+		return "never"
+	}
+	return f.Ago(f.Since(f.LastModified))
+}
+
+func (f Flag) LastRequestedAgo() string {
+	if f.RequestsUnavailable {
+		return "unavailable"
+	}
+	if f.StatusUnknown {
+		return "unknown"
+	}
+	if f.LastRequested.IsZero() {
+		return "never"
+	}
+	return f.Ago(f.Since(f.LastRequested))
+}
+
+func (f Flag) LastRequestedAgoIn(env string) string {
+	lastRequested := f.Environments[env].LastRequested
+	if f.RequestsUnavailable {
+		return "unavailable"
+	}
+	if f.Environments[env].StatusUnknown {
+		return "unknown"
+	}
+	if lastRequested.IsZero() {
+		return "never"
+	}
+	return f.Ago(f.Since(lastRequested))
+}
+
+// Since is time.Since, relative to AsOf when set.
+func (f Flag) Since(t time.Time) time.Duration {
+	if f.AsOf.IsZero() {
+		return time.Since(t)
+	}
+	return f.AsOf.Sub(t)
+}
+
+// Ago describes a duration for humans, e.g. "3.5 months ago".
+func (f Flag) Ago(ago time.Duration) string {
+	switch {
+	case ago > 365*24*time.Hour:
+		return fmt.Sprintf("%.1f years ago", float64(ago)/float64(24*time.Hour*365))
+	case ago > 30*24*time.Hour:
+		return fmt.Sprintf("%.1f months ago", float64(ago)/float64(24*time.Hour*30))
+	case ago > 24*time.Hour:
+		return fmt.Sprintf("%.1f days ago", float64(ago)/float64(24*time.Hour))
+	case ago > time.Hour:
+		return fmt.Sprintf("%.1f hours ago", float64(ago)/float64(time.Hour))
+	case ago > time.Minute:
+		return fmt.Sprintf("%.1f minutes ago", float64(ago)/float64(time.Minute))
+	default:
+		return fmt.Sprintf("%.0f seconds ago", float64(ago)/float64(time.Second))
+	}
+}
+
+// PreciseAgo is like Ago, but down to the minute, e.g. "31d 4h 12m ago".
+func PreciseAgo(ago time.Duration) string {
+	ago = ago.Truncate(time.Minute)
+	days := ago / (24 * time.Hour)
+	hours := (ago % (24 * time.Hour)) / time.Hour
+	minutes := (ago % time.Hour) / time.Minute
+	return fmt.Sprintf("%dd %dh %dm ago", days, hours, minutes)
+}
+
+// Age is the time since the flag was created.
+func (f Flag) Age() time.Duration {
+	if f.CreationDate.IsZero() {
+		return 0
+	}
+	return f.Since(f.CreationDate)
+}
+
+// Overdue tells whether the flag is temporary but older than limit, so it
+// outlived the short life it was meant to have.
+func (f Flag) Overdue(limit time.Duration) bool {
+	return limit > 0 && f.Temporary && f.Age() > limit
+}
+
+// InactiveFor is the time since the flag was last requested, or since it was
+// created if it was never requested. It is zero when the status is unknown,
+// LastRequested being zero then for lack of data rather than of requests.
+func (f Flag) InactiveFor() time.Duration {
+	if f.StatusUnknown {
+		return 0
+	}
+	if f.LastRequested.IsZero() {
+		return f.Age()
+	}
+	return f.Since(f.LastRequested)
+}
+
+// IsPrerequisite tells whether other flags depend on this one, so removing
+// it would break them.
+func (f Flag) IsPrerequisite() bool {
+	return len(f.RequiredBy) > 0
+}
+
+// Link returns the web app link to the flag in env: the one returned by the
+// API when available, since it survives URL scheme changes, or one built
+// from the project, env and key otherwise. A tab other than overview, e.g.
+// targeting or settings, is appended to open that tab of the flag.
+func (f Flag) Link(host, env, tab string) string {
+	var link string
+	switch {
+	case strings.HasPrefix(f.Site, "http://"), strings.HasPrefix(f.Site, "https://"):
+		link = f.Site
+	case f.Site != "":
+		link = host + f.Site
+	default:
+		link = host + "/" + url.PathEscape(f.Project) + "/" + url.PathEscape(env) + "/features/" + url.PathEscape(f.Key)
+	}
+	if tab == "" || tab == "overview" {
+		return link
+	}
+	return strings.TrimSuffix(link, "/") + "/" + tab
+}
+
+// Inactive tells whether the flag is known not to be requested for longer
+// than threshold, false when LaunchDarkly had no status for it or it has no
+// checked environment.
+func (f Flag) Inactive(threshold time.Duration) bool {
+	return !f.StatusUnknown && !f.Misconfigured && f.LastRequestedMoreThan(threshold)
+}
+
+// GetStatus is inactive or inuse by the last requested time, unknown
+// without a status and misconfigured without the checked environment.
+func (f Flag) GetStatus(threshold time.Duration) string {
+	switch {
+	case f.Misconfigured:
+		return "misconfigured"
+	case f.StatusUnknown:
+		return "unknown"
+	case f.Inactive(threshold):
+		return "inactive"
+	default:
+		return "inuse"
+	}
+}
+
+// GetStatusIn is like GetStatus, for the last requested time in env.
+func (f Flag) GetStatusIn(env string, threshold time.Duration) string {
+	status := f.Environments[env]
+	switch {
+	case status.StatusUnknown:
+		return "unknown"
+	case status.LastRequested.IsZero() || f.Since(status.LastRequested) > threshold:
+		return "inactive"
+	default:
+		return "inuse"
+	}
+}
+
+func (f Flag) GetTemporary() string {
+	if f.Temporary {
+		return "temporary"
+	}
+	return "permanent"
+}
+
+type Client struct {
+	Client    http.Client
+	ApiKey    string
+	Host      string
+	FirstPage string
+	QueryUrl  string
+
+	// Search limits GetFlags to flags LaunchDarkly finds by this text in
+	// their key, name or description. It is applied server-side, together
+	// with the filter to live flags.
+	Search string
+
+	// APIVersion is sent as LD-API-Version with GET requests when set. The
+	// flag statuses query is only available in the beta version, so POST
+	// requests always use beta.
+	APIVersion string
+
+	// Retries is how many times a request is retried after a 429 or 5xx
+	// response or a transient network error.
+	Retries int
+
+	// RequestTimeout bounds every single get and post, retries included,
+	// so one slow call fails fast instead of eating the whole budget of
+	// the caller's context, which remains the upper bound.
+	RequestTimeout time.Duration
+
+	// Checkpoint is an optional path where GetFlags records its progress
+	// after every page. With Resume set, an existing checkpoint is used to
+	// continue pagination instead of starting over.
+	Checkpoint string
+	Resume     bool
+
+	// Headers are sent with every request. They are applied after the
+	// default headers, so naming e.g. Authorization here replaces it.
+	Headers http.Header
+
+	// ModifiedAnyEnv makes Flag.LastModified the latest modification in
+	// any environment of the flag rather than only in the checked one. It
+	// requires fetching all environments in the listing.
+	ModifiedAnyEnv bool
+
+	// AllEnvs fetches all environments in the listing, so Flag.Constant is
+	// judged by every environment of the flag rather than only the checked
+	// and status ones.
+	AllEnvs bool
+
+	// StatusBatchSize limits how many flag keys are sent in one status
+	// query, all keys of a page are sent at once when zero.
+	StatusBatchSize int
+
+	// StatusEnvs are environments to fetch data for in addition to the
+	// checked one, available in Flag.Environments.
+	StatusEnvs []string
+
+	// MembersCache is an optional file where GetMembers keeps the member
+	// list for MembersCacheTTL, so repeated runs don't refetch it.
+	MembersCache    string
+	MembersCacheTTL time.Duration
+
+	// MaxResponseBytes caps the size of a response body, unlimited when
+	// zero.
+	MaxResponseBytes int64
+
+	// Verbose receives a log line per request when set.
+	Verbose io.Writer
+
+	// Events is called with every step of fetching flags when set.
+	Events func(Event)
+
+	// RateLimitThreshold makes requests wait for the rate limit reset once
+	// the remaining budget reported by LaunchDarkly drops to it. Zero
+	// disables waiting.
+	RateLimitThreshold int
+
+	// Limiter paces every request, retries included, when set.
+	Limiter *rate.Limiter
+
+	// RunID is sent with a sequence number as X-Request-Id with every
+	// request, so a run can be traced in LaunchDarkly's logs.
+	RunID    string
+	requests atomic.Int64
+
+	statsMu sync.Mutex
+	calls   map[string]int
+	bytes   atomic.Int64
+
+	membersMu sync.Mutex
+	members   []Member
+
+	// statusesUnavailable is set once the flag status query failed as
+	// not available, it isn't sent again then.
+	statusesUnavailable atomic.Bool
+
+	rateLimitMu    sync.Mutex
+	rateLimitUntil time.Time
+}
+
+// DefaultHost is the API host used unless the client's Host is set.
+const DefaultHost = "https://app.launchdarkly.com"
+
+const pageSize = 50
+
+// firstPage lists live flags, matching search by LaunchDarkly's full-text
+// query filter when set.
+func firstPage(project string, envs []string, search string) string {
+	filter := "state:live"
+	if search != "" {
+		filter += ",query:" + search
+	}
+
+	page := "/api/v2/flags/" + url.PathEscape(project) + "?limit=" + strconv.Itoa(pageSize)
+	for _, env := range envs {
+		page += "&env=" + url.QueryEscape(env)
+	}
+	return page + "&sort=creationDate&filter=" + url.QueryEscape(filter) + "&summary=0"
+}
+
+func queryUrl(project string) string {
+	return "/api/v2/projects/" + url.PathEscape(project) + "/flag-statuses/queries"
+}
+
+func (cli *Client) host() string {
+	if cli.Host != "" {
+		return cli.Host
+	}
+	return DefaultHost
+}
+
+func (cli *Client) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if cli.RequestTimeout > 0 {
+		return context.WithTimeout(ctx, cli.RequestTimeout)
+	}
+	return ctx, func() {}
+}
+
+func (cli *Client) get(ctx context.Context, url string, out interface{}) error {
+	ctx, cancel := cli.requestContext(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", cli.host()+url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", cli.ApiKey)
+	req.Header.Set("Accept", "application/json")
+	if cli.APIVersion != "" {
+		req.Header.Set("LD-API-Version", cli.APIVersion)
+	}
+	cli.setHeaders(req)
+	resp, err := cli.do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+
+	if err := json.NewDecoder(cli.body(resp)).Decode(out); err != nil {
+		return &DecodeError{URL: req.URL.String(), Err: err}
+	}
+
+	return nil
+}
+
+func (cli *Client) post(ctx context.Context, url string, in, out interface{}) error {
+	ctx, cancel := cli.requestContext(ctx)
+	defer cancel()
+
+	inBuffer := bytes.NewBuffer([]byte{})
+	if err := json.NewEncoder(inBuffer).Encode(in); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", cli.host()+url, inBuffer)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", cli.ApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("LD-API-Version", "beta")
+	cli.setHeaders(req)
+	resp, err := cli.do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+
+	if err := json.NewDecoder(cli.body(resp)).Decode(out); err != nil {
+		return &DecodeError{URL: req.URL.String(), Err: err}
+	}
+
+	return nil
+}
+
+// do sends the request, retrying up to cli.Retries times with exponential
+// backoff while the response is 429 or 5xx, or the request failed with a
+// transient network error.
+func (cli *Client) do(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := cli.waitRateLimit(req.Context()); err != nil {
+			return nil, err
+		}
+		if cli.Limiter != nil {
+			if err := cli.Limiter.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+
+		cli.countCall(req.Method)
+		resp, err := cli.Client.Do(req)
+		if err != nil {
+			cli.Logf("%s %s [%s]: %v", req.Method, req.URL, req.Header.Get("X-Request-Id"), err)
+			if attempt >= cli.Retries || req.Context().Err() != nil || !transient(err) {
+				return nil, err
+			}
+		} else {
+			if err := decompress(resp); err != nil {
+				return nil, err
+			}
+			resp.Body = countingReader{resp.Body, &cli.bytes}
+			cli.observeRateLimit(req, resp)
+			if attempt >= cli.Retries || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500) {
+				return resp, nil
+			}
+			resp.Body.Close()
+		}
+
+		if err := sleep(req.Context(), time.Second<<attempt); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// Logf writes a line to Verbose when set.
+func (cli *Client) Logf(format string, args ...interface{}) {
+	if cli.Verbose != nil {
+		fmt.Fprintf(cli.Verbose, format+"\n", args...)
+	}
+}
+
+// observeRateLimit logs the remaining rate limit budget reported in the
+// response headers and, with RateLimitThreshold set, holds off further
+// requests until the reset once the budget runs low.
+func (cli *Client) observeRateLimit(req *http.Request, resp *http.Response) {
+	remaining := -1
+	for _, name := range []string{"X-Ratelimit-Route-Remaining", "X-Ratelimit-Global-Remaining", "X-Ratelimit-Remaining"} {
+		if value, err := strconv.Atoi(resp.Header.Get(name)); err == nil && (remaining < 0 || value < remaining) {
+			remaining = value
+		}
+	}
+
+	var reset time.Time
+	if millis, err := strconv.ParseInt(resp.Header.Get("X-Ratelimit-Reset"), 10, 64); err == nil {
+		reset = time.UnixMilli(millis)
+	}
+
+	if remaining < 0 {
+		cli.Logf("%s %s [%s]: %s", req.Method, req.URL, req.Header.Get("X-Request-Id"), resp.Status)
+		return
+	}
+	cli.Logf("%s %s [%s]: %s, rate limit remaining %d, reset in %s", req.Method, req.URL, req.Header.Get("X-Request-Id"), resp.Status, remaining, time.Until(reset).Round(time.Second))
+
+	if cli.RateLimitThreshold > 0 && remaining <= cli.RateLimitThreshold && !reset.IsZero() {
+		cli.rateLimitMu.Lock()
+		if reset.After(cli.rateLimitUntil) {
+			cli.rateLimitUntil = reset
+		}
+		cli.rateLimitMu.Unlock()
+	}
+}
+
+func (cli *Client) waitRateLimit(ctx context.Context) error {
+	cli.rateLimitMu.Lock()
+	wait := time.Until(cli.rateLimitUntil)
+	cli.rateLimitMu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	cli.Logf("rate limit almost exhausted, waiting %s", wait.Round(time.Second))
+	return sleep(ctx, wait)
+}
+
+// transient tells whether a failed request is worth retrying: timeouts,
+// DNS hiccups and connections reset or closed by the peer.
+func transient(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && (dnsErr.IsTemporary || dnsErr.IsTimeout) {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// decompress undoes a gzip or deflate Content-Encoding. The transport only
+// does it by itself when Accept-Encoding wasn't set explicitly, as it may be
+// with -header.
+func decompress(resp *http.Response) error {
+	var r io.Reader
+	var err error
+	switch strings.ToLower(resp.Header.Get("Content-Encoding")) {
+	case "gzip":
+		r, err = gzip.NewReader(resp.Body)
+	case "deflate":
+		r, err = zlib.NewReader(resp.Body)
+	default:
+		return nil
+	}
+	if err != nil {
+		resp.Body.Close()
+		return err
+	}
+
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{r, resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+func (cli *Client) body(resp *http.Response) io.Reader {
+	if cli.MaxResponseBytes > 0 {
+		return &limitReader{r: resp.Body, limit: cli.MaxResponseBytes, remaining: cli.MaxResponseBytes}
+	}
+	return resp.Body
+}
+
+func (cli *Client) setHeaders(req *http.Request) {
+	for key, values := range cli.Headers {
+		req.Header[key] = values
+	}
+	if cli.RunID != "" {
+		req.Header.Set("X-Request-Id", cli.RunID+"-"+strconv.FormatInt(cli.requests.Add(1), 10))
+	}
+}
+
+// newRunID returns a random UUID.
+func newRunID() string {
+	var id [16]byte
+	rand.Read(id[:])
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:])
+}
+
+type GetResponse struct {
+	Links struct {
+		Next struct {
+			Href string `json:"href"`
+			Type string `json:"type"`
+		} `json:"next"`
+	} `json:"_links"`
+	Items []FlagItem `json:"items"`
+
+	// TotalCount is the number of flags in all pages, zero if not returned.
+	TotalCount int `json:"totalCount"`
+}
+
+type Site struct {
+	Href string `json:"href"`
+}
+
+type FlagItem struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+	Site        Site   `json:"_site"`
+	Maintainer  struct {
+		Email string `json:"email"`
+	} `json:"_maintainer"`
+
+	// Fields of other API versions, older ones returned the maintainer
+	// as maintainer, or only its email as maintainerEmail.
+	LegacyMaintainer struct {
+		Email string `json:"email"`
+	} `json:"maintainer"`
+	LegacyMaintainerEmail string `json:"maintainerEmail"`
+
+	CustomProperties map[string]struct {
+		Name  string   `json:"name"`
+		Value []string `json:"value"`
+	} `json:"customProperties"`
+
+	Temporary    bool                       `json:"temporary"`
+	Tags         []string                   `json:"tags"`
+	Version      int                        `json:"_version"`
+	CreationDate Millis                     `json:"creationDate"`
+	Environments map[string]FlagEnvironment `json:"environments"`
+
+	// Lifecycle is only returned to accounts in the flag lifecycle beta.
+	Lifecycle struct {
+		Stage string `json:"stage"`
+	} `json:"_lifecycle"`
+}
+
+type FlagEnvironment struct {
+	Site         Site   `json:"_site"`
+	LastModified Millis `json:"lastModified"`
+	On           bool   `json:"on"`
+	OffVariation *int   `json:"offVariation"`
+	Fallthrough  struct {
+		Variation *int `json:"variation"`
+		Rollout   *struct {
+			Variations []struct {
+				Variation int `json:"variation"`
+				Weight    int `json:"weight"`
+			} `json:"variations"`
+		} `json:"rollout"`
+	} `json:"fallthrough"`
+	Targets       []json.RawMessage `json:"targets"`
+	Rules         []json.RawMessage `json:"rules"`
+	Prerequisites []struct {
+		Key string `json:"key"`
+	} `json:"prerequisites"`
+
+	// ContextTargets are the targets of non-user context kinds. Those of
+	// the user kind are listed without values, which are in Targets.
+	ContextTargets []struct {
+		Values []string `json:"values"`
+	} `json:"contextTargets"`
+}
+
+// Rollout tells whether the flag is off, on and serving a single variation
+// to everyone, or partially rolled out through targets, rules or a
+// percentage rollout.
+func (e FlagEnvironment) Rollout() string {
+	if !e.On {
+		return "off"
+	}
+	if len(e.Targets) > 0 || len(e.Rules) > 0 || e.contextTargeted() {
+		return "partial"
+	}
+	if e.Fallthrough.Rollout != nil {
+		for _, variation := range e.Fallthrough.Rollout.Variations {
+			if variation.Weight != 0 && variation.Weight != 100000 {
+				return "partial"
+			}
+		}
+	}
+	return "on"
+}
+
+// MaintainerEmail returns the maintainer email of the first shape present:
+// the current _maintainer, then the older maintainer object and then the
+// oldest maintainerEmail. The shapes are probed rather than picked by the
+// API version, which is unknown when the token's default is used, and the
+// newest shape wins should a response carry several.
+func (f FlagItem) MaintainerEmail() string {
+	for _, email := range []string{f.Maintainer.Email, f.LegacyMaintainer.Email, f.LegacyMaintainerEmail} {
+		if email != "" {
+			return email
+		}
+	}
+	return ""
+}
+
+// contextTargeted tells whether any context is individually targeted
+// through ContextTargets.
+func (e FlagEnvironment) contextTargeted() bool {
+	for _, target := range e.ContextTargets {
+		if len(target.Values) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Served returns the variation served to everyone in the environment, false
+// when different contexts may get different variations.
+func (e FlagEnvironment) Served() (int, bool) {
+	if !e.On {
+		if e.OffVariation == nil {
+			return 0, false
+		}
+		return *e.OffVariation, true
+	}
+	if len(e.Targets) > 0 || len(e.Rules) > 0 || len(e.Prerequisites) > 0 || e.contextTargeted() {
+		return 0, false
+	}
+	if e.Fallthrough.Variation != nil {
+		return *e.Fallthrough.Variation, true
+	}
+	if e.Fallthrough.Rollout != nil {
+		for _, variation := range e.Fallthrough.Rollout.Variations {
+			if variation.Weight == 100000 {
+				return variation.Variation, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// constant tells whether the flag serves the same variation to everyone in
+// every listed environment, making it effectively a constant.
+func (f FlagItem) constant() bool {
+	served := -1
+	for _, environment := range f.Environments {
+		variation, ok := environment.Served()
+		if !ok || (served >= 0 && variation != served) {
+			return false
+		}
+		served = variation
+	}
+	return served >= 0
+}
+
+func (r *GetResponse) Keys() []string {
+	keys := []string{}
+	for _, item := range r.Items {
+		keys = append(keys, item.Key)
+	}
+	return keys
+}
+
+type PostResponse struct {
+	Items []struct {
+		Key          string `json:"key"`
+		Environments map[string]struct {
+			Name          string    `json:"name"`
+			LastRequested time.Time `json:"lastRequested"`
+		} `json:"environments"`
+	} `json:"items"`
+}
+
+func (r *PostResponse) LastRequested(env string) map[string]time.Time {
+	lastRequested := map[string]time.Time{}
+	for _, item := range r.Items {
+		if value, ok := item.Environments[env]; ok {
+			lastRequested[item.Key] = value.LastRequested
+		}
+	}
+	return lastRequested
+}
+
+// getLastRequested queries flag statuses in batches of StatusBatchSize keys
+// and returns last requested times by environment and flag key. Where the
+// plan doesn't offer the query, it warns once and returns no times at all.
+func (cli *Client) getLastRequested(ctx context.Context, project string, envs, keys []string) (map[string]map[string]time.Time, error) {
+	lastRequested := map[string]map[string]time.Time{}
+	for _, env := range envs {
+		lastRequested[env] = map[string]time.Time{}
+	}
+	if cli.statusesUnavailable.Load() {
+		return lastRequested, nil
+	}
+
+	batchSize := cli.StatusBatchSize
+	if batchSize <= 0 {
+		batchSize = len(keys)
+	}
+
+	for start := 0; start < len(keys); start += batchSize {
+		batch := keys[start:min(start+batchSize, len(keys))]
+
+		var postResponse PostResponse
+		if err := cli.post(ctx, queryUrl(project), map[string]interface{}{
+			"environmentKeys": envs,
+			"flagKeys":        batch,
+		}, &postResponse); err != nil {
+			if statusesUnavailable(err) {
+				if !cli.statusesUnavailable.Swap(true) {
+					Warn("flag statuses are unavailable on this plan, no last requested data, flags are judged by creation and last modified only: %v", err)
+				}
+				return map[string]map[string]time.Time{}, nil
+			}
+			return nil, err
+		}
+
+		for _, env := range envs {
+			maps.Copy(lastRequested[env], postResponse.LastRequested(env))
+		}
+
+		cli.event(Event{Type: "status", Project: project, Keys: len(batch)})
+	}
+
+	return lastRequested, nil
+}
+
+// statusesUnavailable tells whether err is how LaunchDarkly refuses the
+// flag status query on plans without it: 403 or 404, while other requests
+// of the same token succeed.
+func statusesUnavailable(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusForbidden || apiErr.StatusCode == http.StatusNotFound)
+}
+
+// envs returns the checked environment followed by the StatusEnvs.
+func (cli *Client) envs(env string) []string {
+	envs := []string{env}
+	for _, statusEnv := range cli.StatusEnvs {
+		if !slices.Contains(envs, statusEnv) {
+			envs = append(envs, statusEnv)
+		}
+	}
+	return envs
+}
+
+// flag converts a listed flag and its last requested times by environment
+// and key into a Flag checked in env.
+func (cli *Client) flag(project, env string, envs []string, item FlagItem, lastRequested map[string]map[string]time.Time) Flag {
+	maintainerEmail := item.MaintainerEmail()
+	if maintainerEmail == "" {
+		maintainerEmail = "unknown"
+	}
+
+	var prerequisites []string
+	environments := map[string]EnvironmentStatus{}
+	for _, env := range envs {
+		for _, prerequisite := range item.Environments[env].Prerequisites {
+			if !slices.Contains(prerequisites, prerequisite.Key) {
+				prerequisites = append(prerequisites, prerequisite.Key)
+			}
+		}
+		_, known := lastRequested[env][item.Key]
+		environments[env] = EnvironmentStatus{
+			LastModified:  item.Environments[env].LastModified.Time(),
+			LastRequested: lastRequested[env][item.Key],
+			StatusUnknown: !known,
+		}
+	}
+
+	lastModified := item.Environments[env].LastModified
+	if cli.ModifiedAnyEnv {
+		for _, environment := range item.Environments {
+			lastModified = max(lastModified, environment.LastModified)
+		}
+	}
+
+	_, statusKnown := lastRequested[env][item.Key]
+	_, configured := item.Environments[env]
+
+	var customProperties map[string][]string
+	for key, property := range item.CustomProperties {
+		if customProperties == nil {
+			customProperties = map[string][]string{}
+		}
+		customProperties[key] = property.Value
+		if property.Name != "" {
+			customProperties[property.Name] = property.Value
+		}
+	}
+
+	site := item.Environments[env].Site.Href
+	if site == "" {
+		site = item.Site.Href
+	}
+
+	return Flag{
+		Key:                 item.Key,
+		Description:         item.Description,
+		Project:             project,
+		MaintainerEmail:     maintainerEmail,
+		CreationDate:        item.CreationDate.Time(),
+		LastModified:        lastModified.Time(),
+		LastRequested:       lastRequested[env][item.Key],
+		StatusUnknown:       !statusKnown,
+		RequestsUnavailable: cli.statusesUnavailable.Load(),
+		Misconfigured:       !configured,
+		Temporary:           item.Temporary,
+		Tags:                item.Tags,
+		Version:             item.Version,
+		Rollout:             item.Environments[env].Rollout(),
+		Prerequisites:       prerequisites,
+		Environments:        environments,
+		Site:                site,
+		Lifecycle:           item.Lifecycle.Stage,
+		Constant:            item.constant(),
+		CustomProperties:    customProperties,
+	}
+}
+
+// GetFlag fetches a single flag and its status, much faster than GetFlags
+// when only one flag is of interest.
+func (cli *Client) GetFlag(ctx context.Context, project, env, key string) (Flag, error) {
+	envs := cli.envs(env)
+
+	var item FlagItem
+	if err := cli.get(ctx, flagUrl(project, key, cli.listEnvs(envs)), &item); err != nil {
+		return Flag{}, err
+	}
+
+	lastRequested, err := cli.getLastRequested(ctx, project, envs, []string{item.Key})
+	if err != nil {
+		return Flag{}, err
+	}
+
+	return cli.flag(project, env, envs, item, lastRequested), nil
+}
+
+// listEnvs returns the environments to list flags with, all of them when
+// last modified is taken from any environment or AllEnvs is set.
+func (cli *Client) listEnvs(envs []string) []string {
+	if cli.ModifiedAnyEnv || cli.AllEnvs {
+		return nil
+	}
+	return envs
+}
+
+func (cli *Client) GetFlags(ctx context.Context, project, env string) ([]Flag, error) {
+	var flags []Flag
+
+	url := firstPage(project, cli.listEnvs(cli.envs(env)), cli.Search)
+	if cli.Checkpoint != "" && cli.Resume {
+		checkpoint, err := loadCheckpoint(cli.Checkpoint)
+		if err != nil {
+			return nil, err
+		}
+		if checkpoint != nil {
+			if checkpoint.Project != project || checkpoint.Env != env {
+				return nil, fmt.Errorf("checkpoint %s is for project %s and env %s", cli.Checkpoint, checkpoint.Project, checkpoint.Env)
+			}
+			flags, url = checkpoint.Flags, checkpoint.Next
+		}
+	}
+
+	if err := cli.pages(ctx, project, env, url, len(flags), func(page []Flag, next string) error {
+		flags = append(flags, page...)
+		if cli.Checkpoint != "" {
+			if err := saveCheckpoint(cli.Checkpoint, &Checkpoint{
+				Project: project,
+				Env:     env,
+				Next:    next,
+				Flags:   flags,
+			}); err != nil {
+				return fmt.Errorf("failed to save checkpoint: %w", err)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if cli.Checkpoint != "" {
+		if err := os.Remove(cli.Checkpoint); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove checkpoint: %w", err)
+		}
+	}
+
+	return flags, nil
+}
+
+// StreamFlags is like GetFlags, but passes the flags of every page to page
+// as soon as it is fetched instead of collecting them, so memory stays
+// bounded by the page size. Checkpoint is not used.
+func (cli *Client) StreamFlags(ctx context.Context, project, env string, page func([]Flag) error) error {
+	url := firstPage(project, cli.listEnvs(cli.envs(env)), cli.Search)
+	return cli.pages(ctx, project, env, url, 0, func(flags []Flag, _ string) error {
+		return page(flags)
+	})
+}
+
+// pages fetches the listing from url on, fetched flags being already
+// known, and calls page with the flags of every page and the link to the
+// next one.
+func (cli *Client) pages(ctx context.Context, project, env, url string, fetched int, page func(flags []Flag, next string) error) error {
+	var nextUrl string
+	var total int
+
+	envs := cli.envs(env)
+
+	// LaunchDarkly signals the last page with an empty or missing next link,
+	// but a next link pointing at an already fetched page would loop forever.
+	visited := map[string]bool{}
+
+	// Continuing by offset relies on the server honoring it, one ignoring
+	// it returns the same page again and again under changing links.
+	seen := map[string]bool{}
+
+	for ; url != ""; url = nextUrl {
+		if visited[url] {
+			Warn("stopping the listing of %s, the next link %s points at an already fetched page", project, url)
+			break
+		}
+		visited[url] = true
+
+		var getResponse GetResponse
+		if err := cli.get(ctx, url, &getResponse); err != nil {
+			// On the first page a mistyped project key is the usual cause,
+			// later ones are links the API returned itself.
+			var notFound *NotFoundError
+			switch {
+			case errors.As(err, &notFound) && len(visited) == 1 && fetched == 0:
+				return fmt.Errorf("project %s not found: %w", project, err)
+			case errors.As(err, &notFound):
+				return fmt.Errorf("page %s of project %s not found: %w", url, project, err)
+			}
+			return err
+		}
+
+		nextUrl = getResponse.Links.Next.Href
+
+		keys := getResponse.Keys()
+		if len(keys) > 0 && !slices.ContainsFunc(keys, func(key string) bool { return !seen[key] }) {
+			Warn("stopping the listing of %s, the page at %s only repeats already fetched flags", project, url)
+			break
+		}
+		for _, key := range keys {
+			seen[key] = true
+		}
+
+		lastRequested, err := cli.getLastRequested(ctx, project, envs, keys)
+		if err != nil {
+			return err
+		}
+
+		flags := make([]Flag, 0, len(getResponse.Items))
+		for _, item := range getResponse.Items {
+			flags = append(flags, cli.flag(project, env, envs, item, lastRequested))
+		}
+		fetched += len(flags)
+
+		if getResponse.TotalCount > 0 {
+			total = getResponse.TotalCount
+		}
+		cli.event(Event{Type: "page", Project: project, Fetched: fetched, Total: total})
+
+		// Some API versions and filters return no next link at all. A full
+		// page then means there may be more, so continue by offset.
+		if nextUrl == "" && len(getResponse.Items) == pageSize {
+			nextUrl = firstPage(project, cli.listEnvs(envs), cli.Search) + "&offset=" + strconv.Itoa(fetched)
+		}
+
+		if err := page(flags, nextUrl); err != nil {
+			return err
+		}
+	}
+
+	cli.event(Event{Type: "done", Project: project, Fetched: fetched, Total: total})
+
+	return nil
+}
+
+// MarkPrerequisites fills RequiredBy of every flag from the Prerequisites
+// of flags in the same project.
+func MarkPrerequisites(flags []Flag) {
+	index := map[string]int{}
+	for i, item := range flags {
+		index[item.Project+"/"+item.Key] = i
+	}
+
+	for _, item := range flags {
+		for _, key := range item.Prerequisites {
+			if i, ok := index[item.Project+"/"+key]; ok {
+				flags[i].RequiredBy = append(flags[i].RequiredBy, item.Key)
+			}
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package launchdarkly
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client of a server answering with handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewClient("token", WithHost(server.URL))
+}
+
+// listing is a flag listing page of keys, without a next link.
+func listing(keys ...string) string {
+	items := ""
+	for i, key := range keys {
+		if i > 0 {
+			items += ","
+		}
+		items += fmt.Sprintf(`{"key":%q,"environments":{"production":{"lastModified":%d}}}`, key, time.Now().UnixMilli())
+	}
+	return `{"items":[` + items + `]}`
+}
+
+func TestGetFlagsPartialStatuses(t *testing.T) {
+	requested := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			fmt.Fprintf(w, `{"items":[{"key":"known","environments":{"production":{"lastRequested":%q}}}]}`, requested)
+			return
+		}
+		fmt.Fprint(w, listing("known", "missing"))
+	})
+
+	flags, err := client.GetFlags(context.Background(), "default", "production")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flags) != 2 {
+		t.Fatalf("got %d flags, want 2", len(flags))
+	}
+
+	known, missing := flags[0], flags[1]
+	if known.StatusUnknown || known.GetStatus(24*time.Hour) != "inuse" {
+		t.Errorf("flag in the status response is %s, want inuse", known.GetStatus(24*time.Hour))
+	}
+	if !missing.StatusUnknown || missing.GetStatus(24*time.Hour) != "unknown" || missing.Inactive(24*time.Hour) {
+		t.Errorf("flag missing from the status response is %s, want unknown and not inactive", missing.GetStatus(24*time.Hour))
+	}
+	if missing.LastRequestedAgo() != "unknown" {
+		t.Errorf("flag missing from the status response was last requested %s, want unknown", missing.LastRequestedAgo())
+	}
+}
+
+func TestGetFlagsNextLinkCycle(t *testing.T) {
+	var warnings []string
+	defer func(warn func(string, ...interface{})) { Warn = warn }(Warn)
+	Warn = func(format string, args ...interface{}) { warnings = append(warnings, fmt.Sprintf(format, args...)) }
+
+	requests := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			fmt.Fprint(w, `{"items":[]}`)
+			return
+		}
+		requests++
+		// The second page links back to the first one.
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprintf(w, `{"_links":{"next":{"href":%q}},"items":[{"key":"b"}]}`, firstPage("default", []string{"production"}, ""))
+			return
+		}
+		fmt.Fprint(w, `{"_links":{"next":{"href":"/api/v2/flags/default?page=2"}},"items":[{"key":"a"}]}`)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	flags, err := client.GetFlags(ctx, "default", "production")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flags) != 2 || requests != 2 {
+		t.Errorf("got %d flags in %d listing requests, want each page once", len(flags), requests)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("got warnings %q, want one about the cycle", warnings)
+	}
+}
+
+func TestGetFlagsOffsetFallback(t *testing.T) {
+	var warnings []string
+	defer func(warn func(string, ...interface{})) { Warn = warn }(Warn)
+	Warn = func(format string, args ...interface{}) { warnings = append(warnings, fmt.Sprintf(format, args...)) }
+
+	keys := func(from, to int) []string {
+		var keys []string
+		for i := from; i < to; i++ {
+			keys = append(keys, fmt.Sprintf("flag-%d", i))
+		}
+		return keys
+	}
+
+	for _, test := range []struct {
+		name        string
+		honorOffset bool
+		want        int
+		warnings    int
+	}{
+		{"honoring the offset", true, pageSize + 10, 0},
+		{"ignoring the offset", false, pageSize, 1},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			warnings = nil
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost {
+					fmt.Fprint(w, `{"items":[]}`)
+					return
+				}
+				// Neither server returns a next link.
+				if test.honorOffset && r.URL.Query().Get("offset") == strconv.Itoa(pageSize) {
+					fmt.Fprint(w, listing(keys(pageSize, pageSize+10)...))
+					return
+				}
+				fmt.Fprint(w, listing(keys(0, pageSize)...))
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			flags, err := client.GetFlags(ctx, "default", "production")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(flags) != test.want || len(warnings) != test.warnings {
+				t.Errorf("got %d flags and warnings %q, want %d flags and %d warnings", len(flags), warnings, test.want, test.warnings)
+			}
+		})
+	}
+}
+
+func TestGetFlagsEnvWithSpace(t *testing.T) {
+	var envs, statusEnvs []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var query struct {
+				EnvironmentKeys []string `json:"environmentKeys"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+				t.Error(err)
+			}
+			statusEnvs = query.EnvironmentKeys
+			fmt.Fprint(w, `{"items":[]}`)
+			return
+		}
+		envs = r.URL.Query()["env"]
+		fmt.Fprintf(w, `{"items":[{"key":"a","environments":{"qa 2":{"lastModified":%d}}}]}`, time.Now().UnixMilli())
+	})
+
+	flags, err := client.GetFlags(context.Background(), "default", "qa 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(envs) != 1 || envs[0] != "qa 2" {
+		t.Errorf("listed flags with env %q, want \"qa 2\"", envs)
+	}
+	if len(statusEnvs) != 1 || statusEnvs[0] != "qa 2" {
+		t.Errorf("queried statuses of %q, want \"qa 2\"", statusEnvs)
+	}
+	if len(flags) != 1 || flags[0].Misconfigured {
+		t.Fatalf("got %+v, want the flag configured in \"qa 2\"", flags)
+	}
+	if link, want := flags[0].Link("https://app.example.com", "qa 2", "targeting"), "https://app.example.com/default/qa%202/features/a/targeting"; link != want {
+		t.Errorf("got link %s, want %s", link, want)
+	}
+}
+
+func TestGetFlagsProjectNotFound(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"code":"not_found","message":"Unknown project key"}`, http.StatusNotFound)
+	})
+
+	_, err := client.GetFlags(context.Background(), "defualt", "production")
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("got %v, want a NotFoundError", err)
+	}
+	if !strings.HasPrefix(err.Error(), "project defualt not found") {
+		t.Errorf("got %q, want it to name the project", err)
+	}
+}
+
+func TestGetFlagsGzip(t *testing.T) {
+	for _, headers := range []http.Header{nil, {"Accept-Encoding": {"gzip"}}} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body := listing("a")
+			if r.Method == http.MethodPost {
+				body = `{"items":[]}`
+			}
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			fmt.Fprint(gz, body)
+			gz.Close()
+		}))
+		defer server.Close()
+
+		client := NewClient("token", WithHost(server.URL), WithHeaders(headers))
+		flags, err := client.GetFlags(context.Background(), "default", "production")
+		if err != nil {
+			t.Fatalf("Accept-Encoding %q: %v", headers.Get("Accept-Encoding"), err)
+		}
+		if len(flags) != 1 || flags[0].Key != "a" {
+			t.Errorf("Accept-Encoding %q: got %+v, want flag a", headers.Get("Accept-Encoding"), flags)
+		}
+	}
+}
+
+func TestGetFlagsEmptyEnvironments(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			fmt.Fprint(w, `{"items":[]}`)
+			return
+		}
+		fmt.Fprintf(w, `{"items":[{"key":"a","creationDate":%d,"environments":{}}]}`, time.Now().UnixMilli())
+	})
+
+	flags, err := client.GetFlags(context.Background(), "default", "production")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flags) != 1 || !flags[0].Misconfigured {
+		t.Fatalf("got %+v, want a misconfigured flag", flags)
+	}
+	if !flags[0].LastModified.IsZero() || flags[0].Rollout != "off" {
+		t.Errorf("got last modified %v and rollout %s, want none and off", flags[0].LastModified, flags[0].Rollout)
+	}
+}
+
+func TestGetFlagsConstant(t *testing.T) {
+	var envs [][]string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			fmt.Fprint(w, `{"items":[]}`)
+			return
+		}
+		envs = append(envs, r.URL.Query()["env"])
+		fmt.Fprint(w, `{"items":[
+			{"key":"constant","environments":{
+				"production":{"on":true,"fallthrough":{"variation":0}},
+				"staging":{"on":true,"fallthrough":{"variation":0},"contextTargets":[{"contextKind":"user","values":[],"variation":1}]}
+			}},
+			{"key":"targeted","environments":{
+				"production":{"on":true,"fallthrough":{"variation":0}},
+				"staging":{"on":true,"fallthrough":{"variation":0},"contextTargets":[{"contextKind":"device","values":["d1"],"variation":1}]}
+			}}
+		]}`)
+	})
+	client.AllEnvs = true
+
+	flags, err := client.GetFlags(context.Background(), "default", "production")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(envs) != 1 || len(envs[0]) != 0 {
+		t.Errorf("listed flags with env %q, want all environments", envs)
+	}
+	if len(flags) != 2 || !flags[0].Constant || flags[1].Constant {
+		t.Fatalf("got %+v, want only the flag without context targets constant", flags)
+	}
+}
+
+func TestRolloutContextTargets(t *testing.T) {
+	for data, want := range map[string]string{
+		`{"on":true,"contextTargets":[{"contextKind":"user","values":[]}]}`:       "on",
+		`{"on":true,"contextTargets":[{"contextKind":"device","values":["d1"]}]}`: "partial",
+	} {
+		var environment FlagEnvironment
+		if err := json.Unmarshal([]byte(data), &environment); err != nil {
+			t.Fatal(err)
+		}
+		if got := environment.Rollout(); got != want {
+			t.Errorf("rollout of %s = %s, want %s", data, got, want)
+		}
+	}
+}
+
+func TestGetFlagsLaterPageNotFound(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			fmt.Fprint(w, `{"items":[]}`)
+		case r.URL.Query().Get("page") == "2":
+			http.Error(w, `{"code":"not_found"}`, http.StatusNotFound)
+		default:
+			fmt.Fprint(w, `{"_links":{"next":{"href":"/api/v2/flags/default?page=2"}},"items":[{"key":"a"}]}`)
+		}
+	})
+
+	_, err := client.GetFlags(context.Background(), "default", "production")
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("got %v, want a NotFoundError", err)
+	}
+	if !strings.HasPrefix(err.Error(), "page /api/v2/flags/default?page=2 of project default not found") {
+		t.Errorf("got %q, want it to name the page rather than blame the project key", err)
+	}
+}
+
+func TestMaintainerEmailShapes(t *testing.T) {
+	for data, want := range map[string]string{
+		`{"_maintainer":{"email":"current@example.com"},"maintainer":{"email":"old@example.com"},"maintainerEmail":"oldest@example.com"}`: "current@example.com",
+		`{"maintainer":{"email":"old@example.com"},"maintainerEmail":"oldest@example.com"}`:                                               "old@example.com",
+		`{"maintainerEmail":"oldest@example.com"}`: "oldest@example.com",
+		`{"_maintainer":{}}`:                       "",
+	} {
+		var item FlagItem
+		if err := json.Unmarshal([]byte(data), &item); err != nil {
+			t.Fatal(err)
+		}
+		if got := item.MaintainerEmail(); got != want {
+			t.Errorf("maintainer of %s = %q, want %q", data, got, want)
+		}
+	}
+}
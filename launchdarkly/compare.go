@@ -0,0 +1,88 @@
+package launchdarkly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+type FlagResponse struct {
+	FlagItem
+	Variations []struct {
+		Name  string          `json:"name"`
+		Value json.RawMessage `json:"value"`
+	} `json:"variations"`
+}
+
+// variation describes the variation with the given index by its name, or by
+// its value if it has no name.
+func (r *FlagResponse) variation(index *int) string {
+	if index == nil || *index < 0 || *index >= len(r.Variations) {
+		return "-"
+	}
+	if name := r.Variations[*index].Name; name != "" {
+		return name
+	}
+	return string(r.Variations[*index].Value)
+}
+
+func flagUrl(project, key string, envs []string) string {
+	path := "/api/v2/flags/" + url.PathEscape(project) + "/" + url.PathEscape(key) + "?summary=0"
+	for _, env := range envs {
+		path += "&env=" + url.QueryEscape(env)
+	}
+	return path
+}
+
+// EnvironmentConfig is the targeting state of a flag in one environment.
+type EnvironmentConfig struct {
+	Env          string
+	On           bool
+	OffVariation string
+	Fallthrough  string
+	Targets      int
+	Rules        int
+	Rollout      string
+}
+
+// CompareFlag fetches a single flag and returns its targeting state in each
+// of envs, or in every environment when envs is empty, ordered by
+// environment key.
+func (cli *Client) CompareFlag(ctx context.Context, project, key string, envs []string) ([]EnvironmentConfig, error) {
+	var flagResponse FlagResponse
+	if err := cli.get(ctx, flagUrl(project, key, envs), &flagResponse); err != nil {
+		return nil, err
+	}
+
+	configs := []EnvironmentConfig{}
+	for env, environment := range flagResponse.Environments {
+		fallthroughVariation := flagResponse.variation(environment.Fallthrough.Variation)
+		if environment.Fallthrough.Rollout != nil {
+			fallthroughVariation = "rollout"
+		}
+
+		configs = append(configs, EnvironmentConfig{
+			Env:          env,
+			On:           environment.On,
+			OffVariation: flagResponse.variation(environment.OffVariation),
+			Fallthrough:  fallthroughVariation,
+			Targets:      len(environment.Targets),
+			Rules:        len(environment.Rules),
+			Rollout:      environment.Rollout(),
+		})
+	}
+
+	for _, env := range envs {
+		if _, ok := flagResponse.Environments[env]; !ok {
+			return nil, fmt.Errorf("flag %s has no environment %s", key, env)
+		}
+	}
+
+	sort.Slice(configs, func(i, j int) bool {
+		return configs[i].Env < configs[j].Env
+	})
+
+	return configs, nil
+}
@@ -0,0 +1,202 @@
+package launchdarkly
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// Dump holds raw API responses by request, recorded with -dump-raw and
+// replayed with -from-file to run reports without network access.
+//
+// A dump is either a single file, or a directory with a numbered file per
+// response in the order they were received.
+type Dump struct {
+	Responses map[string]json.RawMessage `json:"responses"`
+
+	mu    sync.Mutex
+	path  string
+	dir   bool
+	count int
+}
+
+// DumpEntry is a single response of a directory dump.
+type DumpEntry struct {
+	Request  string          `json:"request"`
+	Response json.RawMessage `json:"response"`
+}
+
+// NewDump returns an empty dump saved to path as responses are recorded,
+// as separate files if path is an existing directory.
+func NewDump(path string) *Dump {
+	info, err := os.Stat(path)
+	return &Dump{
+		Responses: map[string]json.RawMessage{},
+		path:      path,
+		dir:       err == nil && info.IsDir(),
+	}
+}
+
+// LoadDump reads a dump saved to path, a file or a directory.
+func LoadDump(path string) (*Dump, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dump := &Dump{Responses: map[string]json.RawMessage{}}
+	if !info.IsDir() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, dump); err != nil {
+			return nil, err
+		}
+		return dump, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(path, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var entry DumpEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		dump.Responses[entry.Request] = entry.Response
+	}
+
+	return dump, nil
+}
+
+// add records a response and saves it, rewriting a single file dump
+// entirely.
+func (d *Dump) add(key string, response json.RawMessage) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.Responses[key] = response
+	d.count++
+
+	if d.dir {
+		data, err := json.Marshal(DumpEntry{Request: key, Response: response})
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(d.path, fmt.Sprintf("%05d.json", d.count)), data, 0o644)
+	}
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(d.path, data, 0o644)
+}
+
+// dumpKey identifies a request independently of the host it was sent to.
+func dumpKey(req *http.Request) (string, error) {
+	key := req.Method + " " + req.URL.RequestURI()
+	if req.GetBody == nil {
+		return key, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	return key + " " + string(data), nil
+}
+
+// RecordTransport saves every successful json response to the dump.
+type RecordTransport struct {
+	Next http.RoundTripper
+	Dump *Dump
+}
+
+func (t *RecordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := dumpKey(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.Next.RoundTrip(req)
+	if err != nil || resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, err
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	if !json.Valid(data) {
+		return resp, nil
+	}
+
+	if err := t.Dump.add(key, data); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// ReplayTransport answers requests from the dump, failing requests that
+// were never recorded with a NotRecordedError. No status is made up for
+// them, it would be taken for a real answer, e.g. a 404 for flag statuses
+// unavailable on the plan.
+type ReplayTransport struct {
+	Dump *Dump
+}
+
+// NotRecordedError is returned for a request missing from the dump,
+// usually because it was recorded with other options.
+type NotRecordedError struct {
+	Request string
+}
+
+func (e *NotRecordedError) Error() string {
+	return "not in the -from-file dump: " + e.Request
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := dumpKey(req)
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := t.Dump.Responses[key]
+	if !ok {
+		return nil, &NotRecordedError{Request: key}
+	}
+
+	return &http.Response{
+		Status:     strconv.Itoa(http.StatusOK) + " " + http.StatusText(http.StatusOK),
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewReader(data)),
+		Request:    req,
+	}, nil
+}
@@ -0,0 +1,26 @@
+package launchdarkly
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestReplayNotRecorded(t *testing.T) {
+	// The flag status query was never recorded.
+	dump := &Dump{Responses: map[string]json.RawMessage{
+		"GET " + firstPage("default", []string{"production"}, ""): json.RawMessage(listing("a")),
+	}}
+	client := NewClient("token", WithHTTPClient(&http.Client{Transport: &ReplayTransport{Dump: dump}}))
+
+	_, err := client.GetFlags(context.Background(), "default", "production")
+	var notRecorded *NotRecordedError
+	if !errors.As(err, &notRecorded) {
+		t.Fatalf("got %v, want a NotRecordedError", err)
+	}
+	if client.statusesUnavailable.Load() {
+		t.Error("flag statuses taken as unavailable on the plan")
+	}
+}
@@ -0,0 +1,157 @@
+package launchdarkly
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIError is returned for any response with a 4xx or 5xx status. More
+// specific errors below wrap it, so errors.As with *APIError matches them
+// as well.
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: %s: %s", e.Method, e.URL, http.StatusText(e.StatusCode), e.Body)
+}
+
+// AuthError is returned for 401 and 403 responses, usually a missing,
+// invalid or insufficiently privileged api token.
+type AuthError struct {
+	*APIError
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.APIError
+}
+
+// NotFoundError is returned for 404 responses.
+type NotFoundError struct {
+	*APIError
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return e.APIError
+}
+
+// RateLimitError is returned for 429 responses once retries are exhausted.
+// RetryAfter is zero when the response did not say when to retry.
+type RateLimitError struct {
+	*APIError
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.APIError
+}
+
+// DecodeError is returned when a successful response can't be decoded.
+type DecodeError struct {
+	URL string
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to decode %s: %v", e.URL, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ResponseTooLargeError is returned when a response body exceeds the
+// client's MaxResponseBytes.
+type ResponseTooLargeError struct {
+	Limit int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response body exceeds %d bytes", e.Limit)
+}
+
+// limitReader reads at most limit bytes from r and fails with
+// ResponseTooLargeError if there is more.
+type limitReader struct {
+	r         io.Reader
+	limit     int64
+	remaining int64
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		var probe [1]byte
+		n, err := l.r.Read(probe[:])
+		if n > 0 {
+			return 0, &ResponseTooLargeError{Limit: l.limit}
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// checkResponse returns nil for successful responses and one of the errors
+// above otherwise.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	apiErr := &APIError{
+		Method:     resp.Request.Method,
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(body)),
+	}
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &AuthError{apiErr}
+	case http.StatusNotFound:
+		return &NotFoundError{apiErr}
+	case http.StatusTooManyRequests:
+		return &RateLimitError{apiErr, retryAfter(resp)}
+	default:
+		return apiErr
+	}
+}
+
+// retryAfter parses the Retry-After header, given either in seconds or as
+// a date, falling back to LaunchDarkly's X-Ratelimit-Reset in epoch millis.
+func retryAfter(resp *http.Response) time.Duration {
+	if value := resp.Header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+		if date, err := http.ParseTime(value); err == nil {
+			return time.Until(date)
+		}
+	}
+	if value := resp.Header.Get("X-Ratelimit-Reset"); value != "" {
+		if millis, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return time.Until(time.UnixMilli(millis))
+		}
+	}
+	return 0
+}
+
+// Warn receives non-fatal problems with the API data, e.g. malformed
+// timestamps, that are worked around rather than failed on. It prints to
+// stderr unless replaced.
+var Warn = func(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "warning: "+format+"\n", args...)
+}
@@ -0,0 +1,29 @@
+package launchdarkly
+
+import (
+	"time"
+)
+
+// Event is a step of fetching flags, reported to Client.Events.
+type Event struct {
+	// Type is "page" after a page of flags is listed, "status" after a
+	// status query and "done" once all flags of the project are fetched.
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	Project string    `json:"project"`
+
+	// Fetched is the number of flags fetched so far, Total the number of
+	// flags in the project, zero if unknown.
+	Fetched int `json:"fetched"`
+	Total   int `json:"total,omitempty"`
+
+	// Keys is the number of flags a status query asked for.
+	Keys int `json:"keys,omitempty"`
+}
+
+func (cli *Client) event(event Event) {
+	if cli.Events != nil {
+		event.Time = time.Now()
+		cli.Events(event)
+	}
+}
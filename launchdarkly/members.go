@@ -0,0 +1,146 @@
+package launchdarkly
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+type Member struct {
+	ID            string `json:"id"`
+	Email         string `json:"email"`
+	PendingInvite bool   `json:"pendingInvite"`
+}
+
+type MembersResponse struct {
+	Links struct {
+		Next struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"_links"`
+	Items []struct {
+		ID            string `json:"_id"`
+		Email         string `json:"email"`
+		PendingInvite bool   `json:"_pendingInvite"`
+	} `json:"items"`
+}
+
+func membersPage() string {
+	return "/api/v2/members?limit=100"
+}
+
+// GetMembers lists all members of the account. LaunchDarkly removes
+// deactivated members from this listing, so anyone absent from it is no
+// longer an active member.
+//
+// Members are account-wide, so the list is fetched once and kept for the
+// lifetime of the client. With MembersCache set it is also kept on disk for
+// MembersCacheTTL.
+func (cli *Client) GetMembers(ctx context.Context) ([]Member, error) {
+	cli.membersMu.Lock()
+	defer cli.membersMu.Unlock()
+
+	if cli.members != nil {
+		return cli.members, nil
+	}
+
+	if cli.MembersCache != "" {
+		members, err := loadMembers(cli.MembersCache, cli.MembersCacheTTL)
+		if err != nil {
+			return nil, err
+		}
+		if members != nil {
+			cli.members = members
+			return members, nil
+		}
+	}
+
+	members, err := cli.fetchMembers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if cli.MembersCache != "" {
+		if err := saveMembers(cli.MembersCache, members); err != nil {
+			return nil, err
+		}
+	}
+
+	cli.members = members
+	return members, nil
+}
+
+func (cli *Client) fetchMembers(ctx context.Context) ([]Member, error) {
+	members := []Member{}
+	var nextUrl string
+
+	for url := membersPage(); url != ""; url = nextUrl {
+		var membersResponse MembersResponse
+		if err := cli.get(ctx, url, &membersResponse); err != nil {
+			return nil, err
+		}
+
+		nextUrl = membersResponse.Links.Next.Href
+
+		for _, item := range membersResponse.Items {
+			members = append(members, Member{
+				ID:            item.ID,
+				Email:         item.Email,
+				PendingInvite: item.PendingInvite,
+			})
+		}
+	}
+
+	return members, nil
+}
+
+// loadMembers reads the on-disk members cache, returning nil when it does not
+// exist or is older than ttl.
+func loadMembers(path string, ttl time.Duration) ([]Member, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if time.Since(info.ModTime()) > ttl {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []Member
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func saveMembers(path string, members []Member) error {
+	data, err := json.Marshal(members)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// MarkOrphaned sets Orphaned on every flag whose maintainer is unknown or is
+// not among the given active members.
+func MarkOrphaned(flags []Flag, members []Member) {
+	active := map[string]bool{}
+	for _, member := range members {
+		active[strings.ToLower(member.Email)] = true
+	}
+
+	for i := range flags {
+		flags[i].Orphaned = !active[strings.ToLower(flags[i].MaintainerEmail)]
+	}
+}
@@ -0,0 +1,75 @@
+package launchdarkly
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option configures a Client created with NewClient.
+type Option func(*Client)
+
+// NewClient returns a client authorized with apiKey. Without options it
+// talks to app.launchdarkly.com with a one minute request timeout and no
+// retries.
+func NewClient(apiKey string, opts ...Option) *Client {
+	cli := &Client{
+		Client: http.Client{Timeout: time.Minute},
+		ApiKey: apiKey,
+		RunID:  newRunID(),
+	}
+	for _, opt := range opts {
+		opt(cli)
+	}
+	return cli
+}
+
+// WithHost sets the API host, e.g. for a relay or a proxy.
+func WithHost(host string) Option {
+	return func(cli *Client) {
+		cli.Host = host
+	}
+}
+
+// WithHTTPClient replaces the underlying HTTP client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(cli *Client) {
+		cli.Client = *client
+	}
+}
+
+// WithTimeout sets the timeout of every single request.
+func WithTimeout(timeout time.Duration) Option {
+	return func(cli *Client) {
+		cli.Client.Timeout = timeout
+	}
+}
+
+// WithRequestTimeout sets the deadline of every get and post, retries
+// included.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(cli *Client) {
+		cli.RequestTimeout = timeout
+	}
+}
+
+// WithRetries sets how many times 429 and 5xx responses and transient
+// network errors are retried.
+func WithRetries(retries int) Option {
+	return func(cli *Client) {
+		cli.Retries = retries
+	}
+}
+
+// WithAPIVersion sets the LD-API-Version sent with GET requests.
+func WithAPIVersion(version string) Option {
+	return func(cli *Client) {
+		cli.APIVersion = version
+	}
+}
+
+// WithHeaders adds headers sent with every request.
+func WithHeaders(headers http.Header) Option {
+	return func(cli *Client) {
+		cli.Headers = headers
+	}
+}
@@ -0,0 +1,50 @@
+package launchdarkly
+
+import (
+	"context"
+)
+
+type Project struct {
+	Key  string
+	Name string
+}
+
+type ProjectsResponse struct {
+	Links struct {
+		Next struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"_links"`
+	Items []struct {
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	} `json:"items"`
+}
+
+func projectsPage() string {
+	return "/api/v2/projects?limit=100"
+}
+
+// GetProjects lists all projects visible to the api key.
+func (cli *Client) GetProjects(ctx context.Context) ([]Project, error) {
+	var projects []Project
+	var nextUrl string
+
+	for url := projectsPage(); url != ""; url = nextUrl {
+		var projectsResponse ProjectsResponse
+		if err := cli.get(ctx, url, &projectsResponse); err != nil {
+			return nil, err
+		}
+
+		nextUrl = projectsResponse.Links.Next.Href
+
+		for _, item := range projectsResponse.Items {
+			projects = append(projects, Project{
+				Key:  item.Key,
+				Name: item.Name,
+			})
+		}
+	}
+
+	return projects, nil
+}
@@ -0,0 +1,67 @@
+package launchdarkly
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// RequestStats counts the API calls a client made, retries included, and
+// the response bytes it read.
+type RequestStats struct {
+	Calls map[string]int `json:"calls"`
+	Bytes int64          `json:"bytes"`
+}
+
+func (s RequestStats) String() string {
+	methods := make([]string, 0, len(s.Calls))
+	for method := range s.Calls {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	var parts []string
+	for _, method := range methods {
+		parts = append(parts, fmt.Sprintf("%d %s", s.Calls[method], method))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "no calls")
+	}
+	return fmt.Sprintf("%s, %d bytes", strings.Join(parts, ", "), s.Bytes)
+}
+
+// Stats returns the calls made so far.
+func (cli *Client) Stats() RequestStats {
+	cli.statsMu.Lock()
+	defer cli.statsMu.Unlock()
+
+	calls := make(map[string]int, len(cli.calls))
+	for method, count := range cli.calls {
+		calls[method] = count
+	}
+	return RequestStats{Calls: calls, Bytes: cli.bytes.Load()}
+}
+
+func (cli *Client) countCall(method string) {
+	cli.statsMu.Lock()
+	defer cli.statsMu.Unlock()
+
+	if cli.calls == nil {
+		cli.calls = map[string]int{}
+	}
+	cli.calls[method]++
+}
+
+// countingReader adds the bytes read through it to n.
+type countingReader struct {
+	io.ReadCloser
+	n *atomic.Int64
+}
+
+func (r countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,70 @@
+package launchdarkly
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Millis is a Unix timestamp in milliseconds as returned by the API. Besides
+// integers it accepts floats and RFC3339 strings, and an unparsable value
+// becomes zero with a warning rather than failing the whole response.
+//
+// Numbers are checked to be between 2000 and a year from now. Seconds and
+// microseconds are recognized by falling in that range once converted,
+// other values are rejected the same way as unparsable ones.
+type Millis int64
+
+var minMillis = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+
+// toMillis converts a number in milliseconds, or else in seconds or
+// microseconds, to milliseconds, false if none of them is plausible.
+func toMillis(value float64) (Millis, bool) {
+	maxMillis := time.Now().AddDate(1, 0, 0).UnixMilli()
+	for _, millis := range []float64{value, value * 1000, value / 1000} {
+		if millis >= float64(minMillis) && millis <= float64(maxMillis) {
+			return Millis(millis), true
+		}
+	}
+	return 0, false
+}
+
+func (m *Millis) UnmarshalJSON(data []byte) error {
+	*m = 0
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	value := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			*m = Millis(t.UnixMilli())
+			return nil
+		}
+	}
+
+	if millis, err := strconv.ParseInt(value, 10, 64); err == nil && millis == 0 {
+		return nil
+	}
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		if millis, ok := toMillis(number); ok {
+			*m = millis
+			return nil
+		}
+	}
+
+	Warn("ignoring invalid timestamp %s", data)
+	return nil
+}
+
+// Time converts the timestamp, zero staying the zero time.
+func (m Millis) Time() time.Time {
+	if m == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(m))
+}
@@ -0,0 +1,72 @@
+package launchdarkly
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMillisUnmarshal(t *testing.T) {
+	at := time.Date(2023, 5, 17, 12, 30, 0, 0, time.UTC)
+	for data, want := range map[string]time.Time{
+		"1684326600000":               at,
+		"1684326600000.0":             at,
+		`"2023-05-17T12:30:00Z"`:      at,
+		`"2023-05-17T14:30:00+02:00"`: at,
+		"0":                           {},
+		"null":                        {},
+	} {
+		var m Millis
+		if err := json.Unmarshal([]byte(data), &m); err != nil {
+			t.Errorf("unmarshal %s: %v", data, err)
+			continue
+		}
+		if got := m.Time(); !got.Equal(want) {
+			t.Errorf("unmarshal %s = %v, want %v", data, got, want)
+		}
+	}
+}
+
+func TestMillisUnmarshalInvalid(t *testing.T) {
+	var warnings []string
+	defer func(warn func(string, ...interface{})) { Warn = warn }(Warn)
+	Warn = func(format string, args ...interface{}) { warnings = append(warnings, format) }
+
+	for _, data := range []string{`"yesterday"`, "-5", "1e30"} {
+		m := Millis(1)
+		if err := json.Unmarshal([]byte(data), &m); err != nil {
+			t.Errorf("unmarshal %s: %v", data, err)
+		}
+		if m != 0 {
+			t.Errorf("unmarshal %s = %d, want 0", data, m)
+		}
+	}
+	if len(warnings) != 3 {
+		t.Errorf("got %d warnings, want one per invalid timestamp", len(warnings))
+	}
+}
+
+func TestToMillis(t *testing.T) {
+	at := time.Date(2023, 5, 17, 12, 30, 0, 0, time.UTC)
+	for value, want := range map[float64]Millis{
+		float64(at.UnixMilli()):      Millis(at.UnixMilli()),
+		float64(at.Unix()):           Millis(at.UnixMilli()),
+		float64(at.UnixMicro()):      Millis(at.UnixMilli()),
+		float64(at.UnixMilli()) + .5: Millis(at.UnixMilli()),
+	} {
+		if got, ok := toMillis(value); !ok || got != want {
+			t.Errorf("toMillis(%f) = %d, %t, want %d", value, got, ok, want)
+		}
+	}
+
+	for _, value := range []float64{
+		-1,
+		float64(time.Date(1999, 12, 31, 0, 0, 0, 0, time.UTC).UnixMilli()),
+		float64(time.Now().AddDate(2, 0, 0).UnixMilli()),
+		float64(time.Now().AddDate(2, 0, 0).UnixNano()),
+	} {
+		if got, ok := toMillis(value); ok {
+			t.Errorf("toMillis(%f) = %d, want implausible", value, got)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package launchdarkly
+
+import (
+	"context"
+)
+
+// SetMaintainer makes the member with memberID maintain the flag. The
+// comment is recorded in the audit log.
+func (cli *Client) SetMaintainer(ctx context.Context, project, key, memberID, comment string) error {
+	var flagResponse FlagItem
+	return cli.patch(ctx, flagUrl(project, key, nil), map[string]interface{}{
+		"comment": comment,
+		"patch": []map[string]interface{}{
+			{"op": "replace", "path": "/maintainerId", "value": memberID},
+		},
+	}, &flagResponse)
+}
@@ -0,0 +1,45 @@
+package launchdarkly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+)
+
+// VerifyFlags fetches up to sample of flags again through the single flag
+// endpoint and describes every difference from the listed data, to catch
+// inconsistencies of the list and status endpoints.
+func (cli *Client) VerifyFlags(ctx context.Context, env string, flags []Flag, sample int) ([]string, error) {
+	var discrepancies []string
+	for _, i := range rand.Perm(len(flags))[:min(sample, len(flags))] {
+		listed := flags[i]
+
+		single, err := cli.GetFlag(ctx, listed.Project, env, listed.Key)
+		var notFound *NotFoundError
+		if errors.As(err, &notFound) {
+			discrepancies = append(discrepancies, fmt.Sprintf("%s/%s is listed, but not found", listed.Project, listed.Key))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, check := range []struct {
+			name           string
+			listed, single any
+			equal          bool
+		}{
+			{"maintainer", listed.MaintainerEmail, single.MaintainerEmail, listed.MaintainerEmail == single.MaintainerEmail},
+			{"version", listed.Version, single.Version, listed.Version == single.Version},
+			{"temporary", listed.Temporary, single.Temporary, listed.Temporary == single.Temporary},
+			{"last modified", listed.LastModified, single.LastModified, listed.LastModified.Equal(single.LastModified)},
+			{"last requested", listed.LastRequested, single.LastRequested, listed.LastRequested.Equal(single.LastRequested)},
+		} {
+			if !check.equal {
+				discrepancies = append(discrepancies, fmt.Sprintf("%s/%s %s is %v listed, but %v alone", listed.Project, listed.Key, check.name, check.listed, check.single))
+			}
+		}
+	}
+	return discrepancies, nil
+}
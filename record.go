@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/truszkowski/launchdarkly-flags/launchdarkly"
+)
+
+// FlagRecord is the structured form of a reported flag, shared by the
+// machine-readable output formats.
+type FlagRecord struct {
+	Key           string     `json:"key"`
+	Project       string     `json:"project"`
+	Maintainer    string     `json:"maintainer"`
+	CreationDate  *time.Time `json:"creationDate"`
+	LastModified  *time.Time `json:"lastModified"`
+	LastRequested *time.Time `json:"lastRequested"`
+	Status        string     `json:"status"`
+	Temporary     bool       `json:"temporary"`
+	Tags          []string   `json:"tags,omitempty"`
+	Version       int        `json:"version"`
+	Rollout       string     `json:"rollout"`
+	Link          string     `json:"link"`
+	CreatedBy     string     `json:"createdBy,omitempty"`
+	Note          string     `json:"note,omitempty"`
+	Description   string     `json:"description,omitempty"`
+	Lifecycle     string     `json:"lifecycle,omitempty"`
+	Constant      bool       `json:"constant,omitempty"`
+
+	// Misconfigured is set for flags without the checked environment, listed
+	// after the reported ones but not judged for staleness.
+	Misconfigured bool `json:"misconfigured,omitempty"`
+
+	// Environments holds the status in every fetched environment, while the
+	// top-level fields are for the checked one.
+	Environments map[string]EnvironmentRecord `json:"environments,omitempty"`
+}
+
+// EnvironmentRecord is the status of a reported flag in one environment.
+type EnvironmentRecord struct {
+	LastModified  *time.Time `json:"lastModified"`
+	LastRequested *time.Time `json:"lastRequested"`
+	Status        string     `json:"status"`
+}
+
+func newRecord(f launchdarkly.Flag, threshold time.Duration, link string) FlagRecord {
+	var environments map[string]EnvironmentRecord
+	for env, status := range f.Environments {
+		if environments == nil {
+			environments = map[string]EnvironmentRecord{}
+		}
+		environments[env] = EnvironmentRecord{
+			LastModified:  timeOrNil(status.LastModified),
+			LastRequested: timeOrNil(status.LastRequested),
+			Status:        f.GetStatusIn(env, threshold),
+		}
+	}
+
+	return FlagRecord{
+		Key:           f.Key,
+		Project:       f.Project,
+		Maintainer:    f.MaintainerEmail,
+		CreationDate:  timeOrNil(f.CreationDate),
+		LastModified:  timeOrNil(f.LastModified),
+		LastRequested: timeOrNil(f.LastRequested),
+		Status:        f.GetStatus(threshold),
+		Temporary:     f.Temporary,
+		Tags:          f.Tags,
+		Version:       f.Version,
+		Rollout:       f.Rollout,
+		Link:          link,
+		CreatedBy:     f.CreatedBy,
+		Note:          f.Note,
+		Description:   f.Description,
+		Lifecycle:     f.Lifecycle,
+		Constant:      f.Constant,
+		Misconfigured: f.Misconfigured,
+		Environments:  environments,
+	}
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// loadRecords reads a report previously written with -format json.
+func loadRecords(path string) ([]FlagRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []FlagRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
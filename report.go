@@ -0,0 +1,1459 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/truszkowski/launchdarkly-flags/launchdarkly"
+)
+
+// selectionFlags are the flags deciding which flags are fetched, which of
+// them are stale and in what order they are reported.
+type selectionFlags struct {
+	threshold         time.Duration
+	flagType          string
+	withPermanent     bool
+	checkpoint        string
+	resume            bool
+	orphaned          bool
+	filterEnvs        string
+	modifiedAnyEnv    bool
+	unknownLast       bool
+	allProjects       bool
+	skipPrerequisites bool
+	maxVersion        int
+	tagExpr           string
+	annotationsFile   string
+	hideAnnotated     bool
+	sortBy            string
+	lifecycle         string
+	concurrency       int
+	maintainer        string
+	maintainerDomain  string
+	verify            int
+	neverRequested    time.Duration
+	requireRequest    bool
+	search            string
+	expectMin         int
+	constantOnly      bool
+	overdueAfter      time.Duration
+	watchKeys         listFlag
+	watchlistFile     string
+	explain           bool
+	asOf              string
+	descriptionRegex  string
+
+	// now is the -now reference time, zero for the current time.
+	now time.Time
+
+	// misconfigured are the fetched flags without the checked environment,
+	// left out of the reported flags by collect and listed separately.
+	misconfigured []launchdarkly.Flag
+
+	gatingEnvs  []string
+	lifecycles  []string
+	tags        TagExpr
+	description *regexp.Regexp
+	annotations Annotations
+	watchlist   Watchlist
+}
+
+func (s *selectionFlags) register(fs *flag.FlagSet) {
+	fs.DurationVar(&s.threshold, "threshold", defaultThreshold, thresholdUsage)
+	fs.StringVar(&s.flagType, "type", "temporary", "which flags to show: temporary, permanent or all")
+	fs.BoolVar(&s.withPermanent, "with-permanent", false, "deprecated, same as -type all")
+	fs.StringVar(&s.checkpoint, "checkpoint", "", "file to record fetch progress in, removed after a successful run")
+	fs.BoolVar(&s.resume, "resume", false, "continue fetching from the -checkpoint file if it exists")
+	fs.BoolVar(&s.orphaned, "orphaned", false, "show only flags whose maintainer is unknown or no longer an active member")
+	fs.StringVar(&s.filterEnvs, "filter-envs", "", "comma-separated environments that must all be unmodified for the threshold (-env by default)")
+	fs.BoolVar(&s.modifiedAnyEnv, "modified-any-env", false, "take last modified as the latest modification in any environment")
+	fs.BoolVar(&s.unknownLast, "unknown-last", false, "list flags without a maintainer after all the others")
+	fs.BoolVar(&s.allProjects, "all-projects", false, "check every project instead of -project")
+	fs.BoolVar(&s.skipPrerequisites, "skip-prerequisites", false, "never show flags that other flags depend on")
+	fs.IntVar(&s.maxVersion, "max-version", 0, "show only flags changed at most this many times (their _version), 0 for any")
+	fs.StringVar(&s.tagExpr, "tag-expr", "", "show only flags whose tags match this expression, e.g. 'team:payments && !keep'")
+	fs.StringVar(&s.descriptionRegex, "description-regex", "", "show only flags whose description matches this regular expression, e.g. '(?i)remove after'")
+	fs.StringVar(&s.annotationsFile, "annotations-file", "", "json file mapping flag keys (or project/key) to notes shown in a column")
+	fs.BoolVar(&s.hideAnnotated, "hide-annotated", false, "don't show flags with a note in -annotations-file")
+	fs.StringVar(&s.lifecycle, "lifecycle", "", "show only flags in these comma-separated lifecycle stages, e.g. ready-for-code-removal")
+	fs.StringVar(&s.maintainer, "maintainer", "", "show only flags maintained by this email")
+	fs.StringVar(&s.maintainerDomain, "maintainer-domain", "", "show only flags whose maintainer email is in this domain, e.g. acme.com")
+	fs.Var(&s.watchKeys, "watch-key", "flag key, or project/key, to always report, marked in a WATCH column, may be repeated")
+	fs.StringVar(&s.watchlistFile, "watchlist-file", "", "file with a -watch-key per line")
+	fs.DurationVar(&s.overdueAfter, "overdue-after", 0, "mark temporary flags older than this as overdue in a column and first with -sort severity, e.g. 2160h")
+	fs.BoolVar(&s.constantOnly, "constant-only", false, "show only flags serving the same variation to everyone in every environment, fetching all environments of the flags")
+	fs.IntVar(&s.expectMin, "expect-min", 0, "fail if fewer flags than this are fetched in total, guarding against a wrong project or filter")
+	fs.StringVar(&s.search, "search", "", "fetch only flags LaunchDarkly finds by this text in their key, name or description")
+	fs.DurationVar(&s.neverRequested, "never-requested", 0, "instead of the threshold, show only flags never requested and created more than this long ago, e.g. 720h")
+	fs.BoolVar(&s.requireRequest, "require-request-data", false, "show only flags with a known last requested, so none is reported without evidence it is unused, at the cost of missing flags never used at all")
+	fs.StringVar(&s.asOf, "now", "", "RFC3339 time to compute ages and staleness relative to instead of the current time, for reproducible or historical reports")
+	fs.BoolVar(&s.explain, "explain-filter", false, "instead of the normal output, list every fetched flag with why it is included or excluded")
+	fs.IntVar(&s.verify, "verify", 0, "fetch this many random reported flags one by one and warn if they differ from the list")
+	fs.IntVar(&s.concurrency, "concurrency", 4, "number of projects fetched in parallel with -all-projects")
+	fs.StringVar(&s.sortBy, "sort", "maintainer", "order of the flags: maintainer, modified or requested (least recent first), or severity (never requested, then longest inactive first, unknown status last)")
+}
+
+// defaultThreshold is the -threshold unless set, half a year.
+const defaultThreshold = 6 * 30 * 24 * time.Hour
+
+// thresholdUsage is the help of -threshold, the same in every command.
+const thresholdUsage = "threshold for last modified and last requested (half-year by default), 0 to list all flags regardless of staleness, judging their status by the default"
+
+// statusThreshold is the threshold flags are judged inactive by in their
+// status, the summary and the highlighting for -threshold: the default one
+// for 0, which lists all flags but would otherwise mark every one inactive.
+// A negative -threshold is refused as invalid usage.
+func statusThreshold(threshold time.Duration) time.Duration {
+	switch {
+	case threshold < 0:
+		usage("-threshold must not be negative")
+	case threshold == 0:
+		return defaultThreshold
+	}
+	return threshold
+}
+
+// statusThreshold is statusThreshold of the -threshold of the selection.
+func (s *selectionFlags) statusThreshold() time.Duration {
+	return statusThreshold(s.threshold)
+}
+
+// parse validates the flags and prepares what they refer to, exiting on
+// invalid usage.
+func (s *selectionFlags) parse(c *connectionFlags) {
+	// Refuses a negative -threshold.
+	s.statusThreshold()
+	if s.resume && s.checkpoint == "" {
+		usage("-resume requires -checkpoint")
+	}
+	if s.allProjects && s.checkpoint != "" {
+		usage("-checkpoint cannot be used with -all-projects")
+	}
+	if s.asOf != "" {
+		now, err := time.Parse(time.RFC3339, s.asOf)
+		if err != nil {
+			usage("invalid -now %q, expected an RFC3339 time like 2024-01-31T00:00:00Z", s.asOf)
+		}
+		s.now = now
+	}
+	if s.requireRequest && s.neverRequested > 0 {
+		usage("-require-request-data cannot be used with -never-requested")
+	}
+
+	switch s.flagType {
+	case "temporary", "permanent", "all":
+	default:
+		usage("invalid -type %q, expected temporary, permanent or all", s.flagType)
+	}
+	if s.withPermanent {
+		warnf("-with-permanent is deprecated, use -type all")
+		if s.flagType == "permanent" {
+			usage("-with-permanent cannot be used with -type permanent")
+		}
+		s.flagType = "all"
+	}
+
+	if s.concurrency < 1 {
+		usage("-concurrency must be at least 1")
+	}
+
+	switch s.sortBy {
+	case "maintainer", "modified", "requested", "severity":
+	default:
+		usage("invalid -sort %q, expected maintainer, modified, requested or severity", s.sortBy)
+	}
+
+	if s.annotationsFile != "" {
+		var err error
+		if s.annotations, err = loadAnnotations(s.annotationsFile); err != nil {
+			usage("invalid -annotations-file: %v", err)
+		}
+	}
+
+	if len(s.watchKeys) > 0 || s.watchlistFile != "" {
+		s.watchlist = Watchlist{}
+		for _, key := range s.watchKeys {
+			s.watchlist[key] = true
+		}
+		if s.watchlistFile != "" {
+			if err := loadWatchlist(s.watchlistFile, s.watchlist); err != nil {
+				usage("invalid -watchlist-file: %v", err)
+			}
+		}
+	}
+
+	if s.tagExpr != "" {
+		var err error
+		if s.tags, err = ParseTagExpr(s.tagExpr); err != nil {
+			usage("invalid -tag-expr: %v", err)
+		}
+	}
+
+	if s.descriptionRegex != "" {
+		var err error
+		if s.description, err = regexp.Compile(s.descriptionRegex); err != nil {
+			usage("invalid -description-regex: %v", err)
+		}
+	}
+
+	s.lifecycles = splitList(s.lifecycle)
+
+	s.gatingEnvs = splitList(s.filterEnvs)
+	if len(s.gatingEnvs) == 0 {
+		s.gatingEnvs = []string{c.env}
+	}
+}
+
+// selected tells whether the flag is reported, marking it as watched when
+// it is because of the watchlist.
+func (s *selectionFlags) selected(item *launchdarkly.Flag) bool {
+	return s.exclusion(item) == ""
+}
+
+// exclusion returns why the flag is not reported, naming the option
+// responsible, or an empty string when it is reported.
+func (s *selectionFlags) exclusion(item *launchdarkly.Flag) string {
+	if item.Misconfigured {
+		return "no data in -env"
+	}
+	if s.watchlist.Watched(*item) {
+		item.Watched = true
+		return ""
+	}
+	switch {
+	case s.neverRequested > 0:
+		switch {
+		case !item.CreationDateMoreThan(s.neverRequested):
+			return "created within -never-requested"
+		case item.StatusUnknown:
+			return "status unknown (-never-requested)"
+		case !item.LastRequested.IsZero():
+			return "requested (-never-requested)"
+		}
+	case s.threshold == 0:
+		// Staleness is ignored, every flag matching the other
+		// filters is listed.
+	default:
+		if !item.CreationDateMoreThan(s.threshold) {
+			return "created within -threshold"
+		}
+		if !item.LastModifiedMoreThanIn(s.gatingEnvs, s.threshold) {
+			return "modified within -threshold"
+		}
+		// LastModified is then the latest modification in any
+		// environment, not only in the listed ones.
+		if s.modifiedAnyEnv && !item.LastModifiedMoreThan(s.threshold) {
+			return "modified within -threshold (-modified-any-env)"
+		}
+	}
+	// A zero last requested means either a flag never evaluated or one
+	// whose evaluation data has aged out, -require-request-data
+	// drops both rather than guess.
+	if s.requireRequest && item.LastRequested.IsZero() {
+		return "no last requested (-require-request-data)"
+	}
+	if s.flagType == "temporary" && !item.Temporary {
+		return "permanent (-type)"
+	}
+	if s.flagType == "permanent" && item.Temporary {
+		return "temporary (-type)"
+	}
+	if s.orphaned && !item.Orphaned {
+		return "maintainer is an active member (-orphaned)"
+	}
+	if s.skipPrerequisites && item.IsPrerequisite() {
+		return "prerequisite of " + strings.Join(item.RequiredBy, ", ") + " (-skip-prerequisites)"
+	}
+	if s.constantOnly && !item.Constant {
+		return "not constant (-constant-only)"
+	}
+	if s.maxVersion > 0 && item.Version > s.maxVersion {
+		return fmt.Sprintf("version %d above -max-version", item.Version)
+	}
+	if s.tags != nil && !s.tags.Match(item.Tags) {
+		return "tags don't match -tag-expr"
+	}
+	if s.description != nil && !s.description.MatchString(item.Description) {
+		return "description doesn't match -description-regex"
+	}
+	if s.maintainer != "" && !strings.EqualFold(item.MaintainerEmail, s.maintainer) {
+		return "maintainer " + item.MaintainerEmail + " (-maintainer)"
+	}
+	if s.maintainerDomain != "" && !strings.EqualFold(emailDomain(item.MaintainerEmail), strings.TrimPrefix(s.maintainerDomain, "@")) {
+		return "maintainer " + item.MaintainerEmail + " (-maintainer-domain)"
+	}
+	if len(s.lifecycles) > 0 && !slices.Contains(s.lifecycles, item.Lifecycle) {
+		return fmt.Sprintf("lifecycle stage %q (-lifecycle)", item.Lifecycle)
+	}
+	if _, ok := s.annotations.Note(*item); ok && s.hideAnnotated {
+		return "annotated (-hide-annotated)"
+	}
+	return ""
+}
+
+// explainFilter writes whether and why every flag is reported, followed by
+// the counts of every result. Misconfigured flags are not reported but
+// listed in a section of their own, so they are neither of the two.
+func (s *selectionFlags) explainFilter(w io.Writer, flags []launchdarkly.Flag) {
+	counts := map[string]int{}
+	tb := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(tb, "PROJECT\tKEY\tRESULT\tREASON")
+	for _, item := range flags {
+		result, reason := "included", s.exclusion(&item)
+		switch {
+		case item.Misconfigured:
+			result = "separate"
+			reason += ", shown separately as misconfigured"
+		case reason != "":
+			result = "excluded"
+		case item.Watched:
+			reason = "watched"
+		}
+		counts[result]++
+		fmt.Fprintf(tb, "%s\t%s\t%s\t%s\n", item.Project, item.Key, result, reason)
+	}
+	tb.Flush()
+	fmt.Fprintf(w, "\n%d included, %d excluded, %d shown separately\n", counts["included"], counts["excluded"], counts["separate"])
+}
+
+// projects configures the client for the selection and returns the keys
+// of the projects to fetch flags of.
+func (s *selectionFlags) projects(ctx context.Context, client *launchdarkly.Client, c *connectionFlags) []string {
+	client.Checkpoint = s.checkpoint
+	client.Resume = s.resume
+	client.ModifiedAnyEnv = s.modifiedAnyEnv
+	client.AllEnvs = s.constantOnly
+	client.Search = s.search
+	client.StatusEnvs = append(client.StatusEnvs, s.gatingEnvs...)
+
+	if !s.allProjects {
+		return []string{c.project}
+	}
+	all, err := client.GetProjects(ctx)
+	if err != nil {
+		fail(fmt.Errorf("failed to get projects: %w", err))
+	}
+	projects := make([]string, 0, len(all))
+	for _, item := range all {
+		projects = append(projects, item.Key)
+	}
+	return projects
+}
+
+// stream is collect for -streaming: instead of collecting, filtering and
+// sorting all flags, every page is filtered and passed to emit as soon as
+// it is fetched, in listing order. Flags are only known to be prerequisites
+// of flags on the same page.
+func (s *selectionFlags) stream(ctx context.Context, client *launchdarkly.Client, c *connectionFlags, emit func([]launchdarkly.Flag)) {
+	var members []launchdarkly.Member
+	projects := s.projects(ctx, client, c)
+	if s.orphaned {
+		var err error
+		if members, err = client.GetMembers(ctx); err != nil {
+			fail(fmt.Errorf("failed to get members: %w", err))
+		}
+	}
+
+	for _, project := range projects {
+		if err := client.StreamFlags(ctx, project, c.env, func(page []launchdarkly.Flag) error {
+			if s.orphaned {
+				launchdarkly.MarkOrphaned(page, members)
+			}
+			launchdarkly.MarkPrerequisites(page)
+
+			selected := page[:0]
+			for _, item := range page {
+				item.AsOf = s.now
+				// Listed, marked by their status, but not judged for
+				// staleness, as after collect.
+				if item.Misconfigured {
+					warnf("%s has no %s environment and is not judged for staleness", item.Key, c.env)
+					selected = append(selected, item)
+					continue
+				}
+				item.Note, _ = s.annotations.Note(item)
+				if s.selected(&item) {
+					selected = append(selected, item)
+				}
+			}
+			emit(selected)
+			return nil
+		}); err != nil {
+			fail(fmt.Errorf("failed to get flags of %s: %w", project, err))
+		}
+	}
+}
+
+// collect fetches the flags, keeps the stale ones and sorts them by -sort:
+// by maintainer, inactive first, oldest first, by last modified or last
+// requested, least recent first, or by how long they are inactive. With
+// -explain-filter it returns all fetched flags, neither filtered nor sorted.
+func (s *selectionFlags) collect(ctx context.Context, client *launchdarkly.Client, c *connectionFlags) []launchdarkly.Flag {
+	// The v2 API lists flags of one project at a time only, there is no
+	// account-wide listing to use instead, so -all-projects goes through the
+	// projects, -concurrency at a time. Flag statuses are per project too.
+	projects := s.projects(ctx, client, c)
+
+	// Every goroutine writes only its own project's slot, merged in project
+	// order once all are done.
+	results := make([][]launchdarkly.Flag, len(projects))
+	errs := make([]error, len(projects))
+	sem := make(chan struct{}, s.concurrency)
+	var wg sync.WaitGroup
+	for i, project := range projects {
+		wg.Add(1)
+		go func(i int, project string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i], errs[i] = client.GetFlags(ctx, project, c.env)
+		}(i, project)
+	}
+	wg.Wait()
+
+	var flags []launchdarkly.Flag
+	for i, project := range projects {
+		if errs[i] != nil {
+			fail(fmt.Errorf("failed to get flags of %s: %w", project, errs[i]))
+		}
+		flags = append(flags, results[i]...)
+	}
+
+	for i := range flags {
+		flags[i].AsOf = s.now
+	}
+
+	if len(flags) < s.expectMin {
+		fail(fmt.Errorf("fetched only %d flags, expected at least %d (-expect-min)", len(flags), s.expectMin))
+	}
+
+	if s.orphaned {
+		members, err := client.GetMembers(ctx)
+		if err != nil {
+			fail(fmt.Errorf("failed to get members: %w", err))
+		}
+		launchdarkly.MarkOrphaned(flags, members)
+	}
+
+	launchdarkly.MarkPrerequisites(flags)
+
+	for i := range flags {
+		flags[i].Note, _ = s.annotations.Note(flags[i])
+	}
+
+	// The caller explains these instead of reporting, see explainFilter.
+	if s.explain {
+		return flags
+	}
+
+	// Without the environment there is nothing to judge staleness by, so
+	// these would only be rows of zero dates.
+	configured := flags[:0]
+	s.misconfigured = nil
+	for _, item := range flags {
+		if item.Misconfigured {
+			s.misconfigured = append(s.misconfigured, item)
+			continue
+		}
+		configured = append(configured, item)
+	}
+	flags = configured
+	if len(s.misconfigured) > 0 {
+		warnf("%d flags have no %s environment and are not judged for staleness", len(s.misconfigured), c.env)
+	}
+
+	filtered := []launchdarkly.Flag{}
+	for _, item := range flags {
+		if s.selected(&item) {
+			filtered = append(filtered, item)
+		}
+	}
+	flags = filtered
+
+	if s.verify > 0 {
+		discrepancies, err := client.VerifyFlags(ctx, c.env, flags, s.verify)
+		if err != nil {
+			fail(fmt.Errorf("failed to verify flags: %w", err))
+		}
+		for _, discrepancy := range discrepancies {
+			warnf("%s", discrepancy)
+		}
+	}
+
+	for _, item := range flags {
+		if item.IsPrerequisite() {
+			warnf("%s is a prerequisite of %s", item.Key, strings.Join(item.RequiredBy, ", "))
+		}
+	}
+
+	sort.Slice(flags, func(i, j int) bool {
+		switch s.sortBy {
+		case "modified":
+			if !flags[i].LastModified.Equal(flags[j].LastModified) {
+				return flags[i].LastModified.Before(flags[j].LastModified)
+			}
+		case "requested":
+			if !flags[i].LastRequested.Equal(flags[j].LastRequested) {
+				return flags[i].LastRequested.Before(flags[j].LastRequested)
+			}
+		case "severity":
+			if overduei, overduej := flags[i].Overdue(s.overdueAfter), flags[j].Overdue(s.overdueAfter); overduei != overduej {
+				return overduei
+			}
+			// Flags of unknown status may or may not be requested, so they
+			// come after those known to be inactive.
+			if unknowni, unknownj := flags[i].StatusUnknown, flags[j].StatusUnknown; unknowni != unknownj {
+				return unknownj
+			}
+			neveri, neverj := flags[i].LastRequested.IsZero(), flags[j].LastRequested.IsZero()
+			if neveri != neverj {
+				return neveri
+			}
+			if inactivei, inactivej := flags[i].InactiveFor(), flags[j].InactiveFor(); inactivei != inactivej {
+				return inactivei > inactivej
+			}
+		}
+
+		if flags[i].MaintainerEmail != flags[j].MaintainerEmail {
+			if s.unknownLast && (flags[i].MaintainerEmail == "unknown" || flags[j].MaintainerEmail == "unknown") {
+				return flags[j].MaintainerEmail == "unknown"
+			}
+			return flags[i].MaintainerEmail < flags[j].MaintainerEmail
+		}
+
+		inactivei := flags[i].Inactive(s.statusThreshold())
+		inactivej := flags[j].Inactive(s.statusThreshold())
+		if inactivei != inactivej {
+			return inactivei
+		}
+
+		if !flags[i].CreationDate.Equal(flags[j].CreationDate) {
+			return flags[i].CreationDate.Before(flags[j].CreationDate)
+		}
+
+		// The key, and the project across projects, make the order
+		// deterministic, so runs can be diffed.
+		if flags[i].Project != flags[j].Project {
+			return flags[i].Project < flags[j].Project
+		}
+		return flags[i].Key < flags[j].Key
+	})
+
+	return flags
+}
+
+// diffFormats are the values of -format that -diff can be used with.
+var diffFormats = []string{"text", "plain", "markdown", "csv", "json", "pretty-json", "ndjson"}
+
+// formats are the values of -format, text being the default.
+var formats = []string{
+	"text",
+	"plain",
+	"markdown",
+	"checklist",
+	"asciidoc",
+	"csv",
+	"confluence",
+	"json",
+	"pretty-json",
+	"ndjson",
+	"slack-blocks",
+	"badge",
+	"influx",
+	"env",
+	"github-actions",
+	"toml",
+}
+
+func runReport(arguments []string) {
+	var connection connectionFlags
+	var selection selectionFlags
+	var format string
+	var diff string
+	var anonymize bool
+	var anonymizeMap string
+	var anonymizeSalt string
+	var createdBy bool
+	var ageHistogram bool
+	var countOnly bool
+	var showVersion bool
+	var showDescription bool
+	var showSummary bool
+	var summaryFile string
+	var colorMode string
+	var noColor bool
+	var timeFormat string
+	var output string
+	var interactive bool
+	var customFields listFlag
+	var assignments string
+	var groupBy bool
+	var requireMaintainer bool
+	var compareEnvs string
+	var groupSort string
+	var streaming bool
+	var sectionHeaders bool
+
+	fs := newFlagSet("report", "")
+	connection.register(fs)
+	selection.register(fs)
+	fs.StringVar(&format, "format", "text", "comma-separated output formats: "+strings.Join(formats, "/")+", plain being unaligned text")
+	fs.Var(&customFields, "custom-field", "custom property, by key or name, to show in a column, may be repeated")
+	fs.BoolVar(&interactive, "interactive", false, "instead of reporting, go through the flags one by one to archive or reassign them")
+	fs.StringVar(&output, "output", "", "comma-separated files to write every -format to, - for stdout (the default)")
+	fs.StringVar(&diff, "diff", "", "report changes against a previous -format json output instead of the full list")
+	fs.BoolVar(&anonymize, "anonymize", false, "replace flag keys, emails, tags and other identifying values with stable tokens, and drop descriptions and notes")
+	fs.StringVar(&anonymizeMap, "anonymize-map", "", "file to save the -anonymize token mapping to")
+	fs.StringVar(&anonymizeSalt, "anonymize-salt", "", "secret to derive -anonymize tokens with, without it guessable keys can be recovered from their tokens")
+	fs.BoolVar(&createdBy, "created-by", false, "look up flag creators in the audit log and show them in a column")
+	fs.BoolVar(&ageHistogram, "age-histogram", false, "print a histogram of reported flags by creation age to stderr")
+	fs.BoolVar(&countOnly, "count-only", false, "print only the number of reported flags")
+	fs.BoolVar(&showVersion, "show-version", false, "show the flag version in a column")
+	fs.BoolVar(&showDescription, "show-description", false, "show the flag description in a column")
+	fs.BoolVar(&showSummary, "summary", false, "print a summary with the flag debt to stderr")
+	fs.StringVar(&summaryFile, "summary-file", "", "file to write the summary to as json")
+	fs.StringVar(&colorMode, "color", "auto", "color the text output: auto (terminal without NO_COLOR set), always or never")
+	fs.BoolVar(&noColor, "no-color", false, "same as -color never")
+	fs.StringVar(&compareEnvs, "compare-envs", "", "two comma-separated environments to show the status in side by side, rows where they differ in yellow")
+	fs.BoolVar(&requireMaintainer, "require-maintainer", false, "exit with code 6 after the report if any stale flag has no maintainer")
+	fs.BoolVar(&groupBy, "group-by-maintainer", false, "show the flags of every maintainer in a separate section")
+	fs.StringVar(&groupSort, "group-sort", "name", "order of -group-by-maintainer sections: name, count (most flags first) or oldest (oldest flag first)")
+	fs.StringVar(&assignments, "assignments", "", "file to write a csv of flags grouped by maintainer to, for splitting cleanup work")
+	fs.BoolVar(&sectionHeaders, "section-headers", false, "split markdown and confluence output into a table per project, each under a project / env heading")
+	fs.BoolVar(&streaming, "streaming", false, "write every page of flags as soon as it is fetched, keeping memory bounded, for a single plain, csv or ndjson -format; the flags are in listing order and neither sorting, grouping nor summaries are available")
+	fs.StringVar(&timeFormat, "time-format", "relative", "how to show last modified and last requested: relative, precise (to the minute) or timestamp")
+	parseFlags(fs, arguments)
+	selection.parse(&connection)
+
+	outputFormats := splitList(strings.ToLower(format))
+	for _, format := range outputFormats {
+		if !slices.Contains(formats, format) {
+			usage("invalid -format %q, expected one of %s", format, strings.Join(formats, ", "))
+		}
+	}
+	if len(outputFormats) == 0 {
+		usage("-format is empty, expected one of %s", strings.Join(formats, ", "))
+	}
+
+	outputs := splitList(output)
+	switch {
+	case output == "":
+		outputs = make([]string, len(outputFormats))
+		for i := range outputs {
+			outputs[i] = "-"
+		}
+	case len(outputs) != len(outputFormats):
+		usage("-output has %d targets for %d -format values", len(outputs), len(outputFormats))
+	}
+
+	if noColor {
+		colorMode = "never"
+	}
+	color, err := useColor(colorMode)
+	if err != nil {
+		usage("invalid -color: %v", err)
+	}
+
+	switch timeFormat {
+	case "relative", "precise", "timestamp":
+	default:
+		usage("invalid -time-format %q, expected relative, precise or timestamp", timeFormat)
+	}
+
+	switch groupSort {
+	case "name", "count", "oldest":
+	default:
+		usage("invalid -group-sort %q, expected name, count or oldest", groupSort)
+	}
+
+	// Streaming never holds all flags, so whatever needs them at once is
+	// refused rather than silently computed from a single page.
+	if streaming {
+		if len(outputFormats) != 1 || !slices.Contains([]string{"plain", "csv", "ndjson"}, outputFormats[0]) {
+			usage("-streaming needs a single -format plain, csv or ndjson")
+		}
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "sort", "unknown-last", "group-by-maintainer", "interactive", "diff", "count-only", "summary", "summary-file",
+				"age-histogram", "assignments", "require-maintainer", "verify", "skip-prerequisites", "expect-min", "checkpoint", "explain-filter":
+				usage("-%s cannot be used with -streaming", f.Name)
+			}
+		})
+	}
+
+	// The other formats have no form for the changes between reports.
+	if diff != "" {
+		for _, format := range outputFormats {
+			if !slices.Contains(diffFormats, format) {
+				usage("-diff cannot be used with -format %s, expected one of %s", format, strings.Join(diffFormats, ", "))
+			}
+		}
+	}
+
+	comparedEnvs := splitList(compareEnvs)
+	if compareEnvs != "" && len(comparedEnvs) != 2 {
+		usage("-compare-envs needs exactly two environments, got %q", compareEnvs)
+	}
+
+	project, env := connection.project, connection.env
+	shownEnvs := connection.shownEnvs()
+	threshold := selection.statusThreshold()
+	allProjects := selection.allProjects
+	annotations := selection.annotations
+
+	client := connection.client()
+	client.StatusEnvs = append(client.StatusEnvs, comparedEnvs...)
+	ctx, cancel := connection.context()
+	defer cancel()
+
+	defer func() { client.Logf("api: %s", client.Stats()) }()
+
+	var flags []launchdarkly.Flag
+	if !streaming {
+		flags = selection.collect(ctx, client, &connection)
+	}
+	if selection.explain {
+		selection.explainFilter(os.Stdout, flags)
+		return
+	}
+	if groupBy {
+		groupByMaintainer(flags, groupSort)
+	}
+
+	if interactive {
+		if err := triage(ctx, client, flags, threshold, "triaged by launchdarkly-flags", os.Stdin, os.Stdout); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	// Checked once the report is out, whatever the output.
+	if requireMaintainer {
+		defer requireMaintainers(flags)
+	}
+
+	// The lifecycle column is only shown when LaunchDarkly provides stages.
+	showLifecycle := len(selection.lifecycles) > 0 || slices.ContainsFunc(flags, func(f launchdarkly.Flag) bool {
+		return f.Lifecycle != ""
+	})
+
+	if countOnly {
+		fmt.Println(len(flags))
+		return
+	}
+
+	// decorate is applied to all flags at once, or to every page when
+	// streaming.
+	var anonymizer *Anonymizer
+	if anonymize {
+		anonymizer = NewAnonymizer(anonymizeSalt)
+	}
+	decorate := func(flags []launchdarkly.Flag) {
+		if createdBy {
+			for i := range flags {
+				member, err := client.GetCreatedBy(ctx, flags[i].Project, flags[i])
+				if err != nil {
+					fail(fmt.Errorf("failed to get creator of %s: %w", flags[i].Key, err))
+				}
+				if member == "" {
+					member = "unknown"
+				}
+				flags[i].CreatedBy = member
+			}
+		}
+		if anonymizer != nil {
+			anonymizer.Anonymize(flags)
+		}
+	}
+	saveAnonymizeMap := func() {
+		if anonymizer != nil && anonymizeMap != "" {
+			if err := anonymizer.Save(anonymizeMap); err != nil {
+				fail(fmt.Errorf("failed to save anonymize mapping: %w", err))
+			}
+		}
+	}
+
+	decorate(flags)
+	decorate(selection.misconfigured)
+	if !streaming {
+		saveAnonymizeMap()
+	}
+
+	if ageHistogram {
+		printAgeHistogram(os.Stderr, flags)
+	}
+
+	summary := summarize(flags, threshold)
+	summary.Requests = client.Stats()
+	if showSummary {
+		summary.Print(os.Stderr)
+	}
+	if summaryFile != "" {
+		if err := summary.Save(summaryFile); err != nil {
+			fail(fmt.Errorf("failed to save summary: %w", err))
+		}
+	}
+
+	link := func(f launchdarkly.Flag) string {
+		if anonymize {
+			return ""
+		}
+		return connection.link(f)
+	}
+
+	if assignments != "" {
+		if err := saveAssignments(assignments, flags, link); err != nil {
+			fail(fmt.Errorf("failed to save assignments: %w", err))
+		}
+	}
+
+	records := make([]FlagRecord, 0, len(flags))
+	for _, item := range flags {
+		records = append(records, newRecord(item, threshold, link(item)))
+	}
+
+	// The table and record formats list the misconfigured flags after the
+	// reported ones, marked by their status, the text format in a section of
+	// its own.
+	listed := append(slices.Clip(flags), selection.misconfigured...)
+	listedRecords := slices.Clip(records)
+	for _, item := range selection.misconfigured {
+		listedRecords = append(listedRecords, newRecord(item, threshold, link(item)))
+	}
+
+	if diff != "" {
+		previous, err := loadRecords(diff)
+		if err != nil {
+			fail(fmt.Errorf("failed to load previous report: %w", err))
+		}
+		previous = slices.DeleteFunc(previous, func(record FlagRecord) bool {
+			return record.Misconfigured
+		})
+
+		delta := diffRecords(previous, records)
+		changes := []struct {
+			name    string
+			records []FlagRecord
+		}{
+			{"new", delta.New},
+			{"removed", delta.Removed},
+			{"remaining", delta.Remaining},
+		}
+		header := []string{"CHANGE", "KEY", "MAINTAINER", "STATUS", "LINK"}
+		var rows [][]string
+		for _, change := range changes {
+			for _, record := range change.records {
+				rows = append(rows, []string{change.name, record.Key, record.Maintainer, record.Status, record.Link})
+			}
+		}
+
+		writeOutputs(outputFormats, outputs, func(w io.Writer, format string, stdout bool) {
+			switch format {
+			case "json", "pretty-json":
+				if err := writeJSON(w, format, delta); err != nil {
+					fail(err)
+				}
+			case "ndjson":
+				encoder := json.NewEncoder(w)
+				for _, change := range changes {
+					for _, record := range change.records {
+						if err := encoder.Encode(struct {
+							Change string `json:"change"`
+							FlagRecord
+						}{change.name, record}); err != nil {
+							fail(err)
+						}
+					}
+				}
+			case "plain", "csv":
+				out := newRowWriter(w, format)
+				for _, row := range append([][]string{header}, rows...) {
+					if err := out.Write(row); err != nil {
+						fail(err)
+					}
+				}
+				out.Flush()
+			case "markdown":
+				separator := make([]string, len(header))
+				for i, column := range header {
+					separator[i] = strings.Repeat("-", len(column))
+				}
+				fmt.Fprintln(w, strings.Join(header, " | "))
+				fmt.Fprintln(w, strings.Join(separator, " | "))
+				for _, row := range rows {
+					cells := make([]string, len(row))
+					for j, value := range row {
+						cells[j] = markdownEscaper.Replace(value)
+					}
+					fmt.Fprintln(w, strings.Join(cells, " | "))
+				}
+			default:
+				tb := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+				fmt.Fprintln(tb, strings.Join(header, "\t"))
+				for _, row := range rows {
+					fmt.Fprintln(tb, strings.Join(row, "\t"))
+				}
+				tb.Flush()
+			}
+		})
+		return
+	}
+
+	header := []string{
+		"KEY",
+		"MAINTAINER",
+		"CREATION DATE",
+		"LAST MODIFIED",
+		"LAST REQUESTED",
+		"STATUS",
+		"TEMPORARY",
+		"ROLLOUT",
+		"LINK",
+	}
+	if allProjects {
+		header = append([]string{"PROJECT"}, header...)
+	}
+	if showVersion {
+		header = append(header, "VERSION")
+	}
+	if showDescription {
+		header = append(header, "DESCRIPTION")
+	}
+	if createdBy {
+		header = append(header, "CREATED BY")
+	}
+	for _, shownEnv := range shownEnvs {
+		header = append(header, "LAST REQUESTED ("+shownEnv+")")
+	}
+	for _, comparedEnv := range comparedEnvs {
+		header = append(header, "STATUS ("+comparedEnv+")")
+	}
+	for _, customField := range customFields {
+		header = append(header, strings.ToUpper(customField))
+	}
+	if selection.watchlist != nil {
+		header = append(header, "WATCH")
+	}
+	if selection.overdueAfter > 0 {
+		header = append(header, "OVERDUE")
+	}
+	if showLifecycle {
+		header = append(header, "LIFECYCLE")
+	}
+	if annotations != nil {
+		header = append(header, "NOTE")
+	}
+
+	when := func(f launchdarkly.Flag, t time.Time, ago string) string {
+		switch {
+		case ago == "unavailable" || ago == "unknown":
+			return ago
+		case t.IsZero():
+			return "never"
+		case timeFormat == "precise":
+			return launchdarkly.PreciseAgo(f.Since(t))
+		case timeFormat == "timestamp":
+			return t.UTC().Format(time.RFC3339)
+		default:
+			return ago
+		}
+	}
+
+	args := func(f launchdarkly.Flag) []string {
+		values := []string{
+			f.Key,
+			f.MaintainerEmail,
+			f.CreationDateAgo(),
+			when(f, f.LastModified, f.LastModifiedAgo()),
+			when(f, f.LastRequested, f.LastRequestedAgo()),
+			f.GetStatus(threshold),
+			f.GetTemporary(),
+			f.Rollout,
+			link(f),
+		}
+		if allProjects {
+			values = append([]string{f.Project}, values...)
+		}
+		if showVersion {
+			values = append(values, strconv.Itoa(f.Version))
+		}
+		if showDescription {
+			// Descriptions may span lines, which would break the rows.
+			values = append(values, strings.Join(strings.Fields(f.Description), " "))
+		}
+		if createdBy {
+			values = append(values, f.CreatedBy)
+		}
+		for _, shownEnv := range shownEnvs {
+			values = append(values, when(f, f.Environments[shownEnv].LastRequested, f.LastRequestedAgoIn(shownEnv)))
+		}
+		for _, comparedEnv := range comparedEnvs {
+			values = append(values, f.GetStatusIn(comparedEnv, threshold))
+		}
+		for _, customField := range customFields {
+			values = append(values, strings.Join(f.CustomProperties[customField], ", "))
+		}
+		if selection.watchlist != nil {
+			watch := ""
+			if f.Watched {
+				watch = "watch"
+			}
+			values = append(values, watch)
+		}
+		if selection.overdueAfter > 0 {
+			overdue := ""
+			if f.Overdue(selection.overdueAfter) {
+				overdue = "overdue"
+			}
+			values = append(values, overdue)
+		}
+		if showLifecycle {
+			values = append(values, f.Lifecycle)
+		}
+		if annotations != nil {
+			values = append(values, f.Note)
+		}
+		return values
+	}
+
+	row := func(f launchdarkly.Flag) []string {
+		values := args(f)
+		if err := checkRow(header, values); err != nil {
+			fail(fmt.Errorf("flag %s: %w", f.Key, err))
+		}
+		return values
+	}
+
+	if streaming {
+		writeOutputs(outputFormats, outputs, func(w io.Writer, format string, stdout bool) {
+			buffered := bufio.NewWriter(w)
+			encoder := json.NewEncoder(buffered)
+			var out rowWriter
+			if format != "ndjson" {
+				out = newRowWriter(buffered, format)
+				if err := out.Write(header); err != nil {
+					fail(err)
+				}
+			}
+			selection.stream(ctx, client, &connection, func(page []launchdarkly.Flag) {
+				decorate(page)
+				for _, item := range page {
+					if format == "ndjson" {
+						if err := encoder.Encode(newRecord(item, threshold, link(item))); err != nil {
+							fail(err)
+						}
+						continue
+					}
+					if err := out.Write(row(item)); err != nil {
+						fail(err)
+					}
+				}
+				if out != nil {
+					out.Flush()
+				}
+				buffered.Flush()
+			})
+			if out != nil {
+				out.Flush()
+			}
+			buffered.Flush()
+		})
+		saveAnonymizeMap()
+		return
+	}
+
+	// Streamed formats write every flag as soon as it is converted, the
+	// others need all rows first.
+	var rows [][]string
+	for _, format := range outputFormats {
+		switch format {
+		case "plain", "csv", "ndjson":
+		default:
+			if rows == nil {
+				rows = make([][]string, 0, len(listed))
+				for _, item := range listed {
+					rows = append(rows, row(item))
+				}
+			}
+		}
+	}
+
+	// sections are the rows of every table, one per project with
+	// -section-headers.
+	sections := [][]int{make([]int, len(listed))}
+	for i := range listed {
+		sections[0][i] = i
+	}
+	if sectionHeaders {
+		sections = projectSections(listed)
+	}
+
+	render := func(w io.Writer, format string, stdout bool) {
+		color := color && stdout
+		switch format {
+		case "json", "pretty-json":
+			if err := writeJSON(w, format, listedRecords); err != nil {
+				fail(err)
+			}
+		case "slack-blocks":
+			title := fmt.Sprintf("Stale flags in %s/%s", project, env)
+			if anonymizer != nil {
+				title = fmt.Sprintf("Stale flags in %s/%s", anonymizer.token("project", project), env)
+			}
+			if allProjects {
+				title = fmt.Sprintf("Stale flags in %s of all projects", env)
+			}
+			message := slackBlocks(title, records)
+			if err := json.NewEncoder(w).Encode(message); err != nil {
+				fail(err)
+			}
+		case "badge":
+			if err := json.NewEncoder(w).Encode(staleBadge(len(flags))); err != nil {
+				fail(err)
+			}
+		case "influx":
+			now := selection.now
+			if now.IsZero() {
+				now = time.Now()
+			}
+			for _, item := range flags {
+				fmt.Fprintln(w, influxLine(env, item, threshold, now))
+			}
+		case "env":
+			var keys, inactiveKeys []string
+			for _, item := range flags {
+				keys = append(keys, item.Key)
+				if item.Inactive(threshold) {
+					inactiveKeys = append(inactiveKeys, item.Key)
+				}
+			}
+			fmt.Fprintf(w, "STALE_FLAG_COUNT=%d\n", len(keys))
+			fmt.Fprintf(w, "STALE_FLAG_KEYS=%s\n", shellQuote(strings.Join(keys, " ")))
+			fmt.Fprintf(w, "INACTIVE_FLAG_COUNT=%d\n", len(inactiveKeys))
+			fmt.Fprintf(w, "INACTIVE_FLAG_KEYS=%s\n", shellQuote(strings.Join(inactiveKeys, " ")))
+		case "github-actions":
+			for _, item := range flags {
+				message := fmt.Sprintf("%s owned by %s, %s", item.Key, item.MaintainerEmail, inactiveFor(item))
+				if link := link(item); link != "" {
+					message += ", " + link
+				}
+				fmt.Fprintf(w, "::warning title=Stale flag::%s\n", githubActionsEscaper.Replace(message))
+			}
+		case "toml":
+			writeTOML(w, "flags", listedRecords)
+		case "markdown":
+			separator := make([]string, len(header))
+			for i, column := range header {
+				separator[i] = strings.Repeat("-", len(column))
+			}
+			for i, section := range sections {
+				if sectionHeaders {
+					if i > 0 {
+						fmt.Fprintln(w)
+					}
+					fmt.Fprintf(w, "## %s / %s\n\n", listed[section[0]].Project, env)
+				}
+				fmt.Fprintln(w, strings.Join(header, " | "))
+				fmt.Fprintln(w, strings.Join(separator, " | "))
+				for _, i := range section {
+					cells := make([]string, len(rows[i]))
+					for j, value := range rows[i] {
+						cells[j] = markdownEscaper.Replace(value)
+					}
+					fmt.Fprintln(w, strings.Join(cells, " | "))
+				}
+			}
+		case "checklist":
+			var maintainers []string
+			byMaintainer := map[string][]launchdarkly.Flag{}
+			for _, item := range flags {
+				if _, ok := byMaintainer[item.MaintainerEmail]; !ok {
+					maintainers = append(maintainers, item.MaintainerEmail)
+				}
+				byMaintainer[item.MaintainerEmail] = append(byMaintainer[item.MaintainerEmail], item)
+			}
+			for i, maintainer := range maintainers {
+				if i > 0 {
+					fmt.Fprintln(w)
+				}
+				fmt.Fprintf(w, "### %s\n\n", maintainer)
+				for _, item := range byMaintainer[maintainer] {
+					line := fmt.Sprintf("- [ ] %s — %s", item.Key, inactiveFor(item))
+					if link := link(item); link != "" {
+						line += " — " + link
+					}
+					fmt.Fprintln(w, line)
+				}
+			}
+		case "asciidoc":
+			link := columnIndex(header, "LINK")
+			fmt.Fprintln(w, `[options="header"]`)
+			fmt.Fprintln(w, "|===")
+			fmt.Fprintln(w, "|"+strings.Join(header, " |"))
+			for _, row := range rows {
+				fmt.Fprintln(w)
+				for j, value := range row {
+					value = asciidocEscaper.Replace(value)
+					if j == link && value != "" {
+						value = "link:" + value + "[" + value + "]"
+					}
+					fmt.Fprintf(w, "|%s\n", value)
+				}
+			}
+			fmt.Fprintln(w, "|===")
+		case "confluence":
+			link := columnIndex(header, "LINK")
+			for _, section := range sections {
+				if sectionHeaders {
+					fmt.Fprintf(w, "<h2>%s</h2>\n", html.EscapeString(listed[section[0]].Project+" / "+env))
+				}
+				fmt.Fprintln(w, "<table><tbody>")
+				fmt.Fprint(w, "<tr>")
+				for _, column := range header {
+					fmt.Fprintf(w, "<th>%s</th>", html.EscapeString(column))
+				}
+				fmt.Fprintln(w, "</tr>")
+				for _, i := range section {
+					row := rows[i]
+					attrs := ""
+					if listed[i].Inactive(threshold) {
+						attrs = ` class="highlight-red" data-highlight-colour="red"`
+					}
+					fmt.Fprint(w, "<tr>")
+					for j, value := range row {
+						value = html.EscapeString(value)
+						if j == link {
+							value = `<a href="` + value + `">` + value + `</a>`
+						}
+						fmt.Fprintf(w, "<td%s>%s</td>", attrs, value)
+					}
+					fmt.Fprintln(w, "</tr>")
+				}
+				fmt.Fprintln(w, "</tbody></table>")
+			}
+		case "plain", "csv":
+			out := newRowWriter(w, format)
+			if err := out.Write(header); err != nil {
+				fail(err)
+			}
+			for i, item := range listed {
+				if err := out.Write(row(item)); err != nil {
+					fail(err)
+				}
+				if (i+1)%flushEvery == 0 {
+					out.Flush()
+				}
+			}
+			out.Flush()
+		case "ndjson":
+			out := bufio.NewWriter(w)
+			encoder := json.NewEncoder(out)
+			for i, item := range listed {
+				if err := encoder.Encode(newRecord(item, threshold, link(item))); err != nil {
+					fail(err)
+				}
+				if (i+1)%flushEvery == 0 {
+					out.Flush()
+				}
+			}
+			out.Flush()
+		default:
+			tb := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+			printHeader := func() {
+				if color {
+					fmt.Fprintln(tb, colorBold+strings.Join(header, "\t")+colorReset)
+				} else {
+					fmt.Fprintln(tb, strings.Join(header, "\t"))
+				}
+			}
+			if !groupBy {
+				printHeader()
+			}
+
+			for i, row := range rows[:len(flags)] {
+				if groupBy && (i == 0 || flags[i].MaintainerEmail != flags[i-1].MaintainerEmail) {
+					if i > 0 {
+						fmt.Fprintln(tb)
+					}
+					count := 0
+					for _, item := range flags[i:] {
+						if item.MaintainerEmail != flags[i].MaintainerEmail {
+							break
+						}
+						count++
+					}
+					fmt.Fprintf(tb, "%s (%d)\n", flags[i].MaintainerEmail, count)
+					printHeader()
+				}
+
+				switch {
+				case !color:
+					fmt.Fprintln(tb, strings.Join(row, "\t"))
+				case len(comparedEnvs) == 2 && flags[i].GetStatusIn(comparedEnvs[0], threshold) != flags[i].GetStatusIn(comparedEnvs[1], threshold):
+					fmt.Fprintln(tb, colorYellow+strings.Join(row, "\t")+colorReset)
+				case flags[i].Inactive(threshold):
+					fmt.Fprintln(tb, colorRed+strings.Join(row, "\t")+colorReset)
+				default:
+					fmt.Fprintln(tb, colorDefault+strings.Join(row, "\t")+colorReset)
+				}
+			}
+
+			tb.Flush()
+
+			if len(selection.misconfigured) > 0 {
+				fmt.Fprintf(w, "\nmisconfigured, no %s environment (%d)\n", env, len(selection.misconfigured))
+				tb := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+				fmt.Fprintln(tb, "PROJECT\tKEY\tMAINTAINER\tCREATION DATE\tLINK")
+				for _, item := range selection.misconfigured {
+					fmt.Fprintf(tb, "%s\t%s\t%s\t%s\t%s\n", item.Project, item.Key, item.MaintainerEmail, item.CreationDateAgo(), link(item))
+				}
+				tb.Flush()
+			}
+		}
+	}
+	writeOutputs(outputFormats, outputs, render)
+}
+
+// requireMaintainers exits with exitUnowned if any of flags has no
+// maintainer.
+func requireMaintainers(flags []launchdarkly.Flag) {
+	unowned := 0
+	for _, item := range flags {
+		if item.MaintainerEmail == "unknown" {
+			unowned++
+		}
+	}
+	if unowned > 0 {
+		printWarnings()
+		fmt.Fprintf(os.Stderr, "error: %d of %d stale flags have no maintainer\n", unowned, len(flags))
+		os.Exit(exitUnowned)
+	}
+}
+
+// inactiveFor describes how long the flag is inactive, or that it is not
+// known without a status.
+func inactiveFor(f launchdarkly.Flag) string {
+	if f.StatusUnknown {
+		return "last requested unknown"
+	}
+	return "inactive for " + strings.TrimSuffix(f.Ago(f.InactiveFor()), " ago")
+}
+
+// flushEvery is how many flags streamed formats write between flushes.
+const flushEvery = 100
+
+// emailDomain returns the part of email after the @, empty for the unknown
+// maintainer.
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return email[at+1:]
+}
+
+// writeOutputs renders every format to its output, - being stdout.
+func writeOutputs(formats, outputs []string, render func(w io.Writer, format string, stdout bool)) {
+	for i, format := range formats {
+		if outputs[i] == "-" {
+			render(os.Stdout, format, true)
+			continue
+		}
+
+		file, err := os.Create(outputs[i])
+		if err != nil {
+			fail(fmt.Errorf("failed to create output: %w", err))
+		}
+		render(file, format, false)
+		if err := file.Close(); err != nil {
+			fail(fmt.Errorf("failed to write output: %w", err))
+		}
+	}
+}
+
+// rowWriter writes the rows of the plain and csv formats.
+type rowWriter interface {
+	Write(row []string) error
+	Flush()
+}
+
+// newRowWriter returns a writer of tab separated rows for plain, and of
+// csv, quoting values with separators or line breaks, for csv.
+func newRowWriter(w io.Writer, format string) rowWriter {
+	if format == "csv" {
+		return csv.NewWriter(w)
+	}
+	return plainWriter{bufio.NewWriter(w)}
+}
+
+type plainWriter struct {
+	w *bufio.Writer
+}
+
+func (p plainWriter) Write(row []string) error {
+	_, err := fmt.Fprintln(p.w, strings.Join(row, "\t"))
+	return err
+}
+
+func (p plainWriter) Flush() {
+	p.w.Flush()
+}
+
+// writeJSON writes v as json, indented for the pretty-json format.
+func writeJSON(w io.Writer, format string, v any) error {
+	encoder := json.NewEncoder(w)
+	if format == "pretty-json" {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(v)
+}
+
+// asciidocEscaper escapes table cell separators.
+var asciidocEscaper = strings.NewReplacer("|", `\|`)
+
+// markdownEscaper escapes table cell separators, and the escape character
+// itself so a trailing backslash does not escape the separator.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`)
+
+// githubActionsEscaper escapes workflow command data.
+var githubActionsEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// shellQuote quotes value for POSIX shells, so it can be safely eval'ed.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// columnIndex returns the position of the named header column, or -1.
+func columnIndex(header []string, name string) int {
+	for i, column := range header {
+		if column == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkRow verifies that a row has exactly one value per header column, so
+// that adding a column in one place but not the other fails loudly instead of
+// silently misaligning the output.
+func checkRow(header, row []string) error {
+	if len(row) != len(header) {
+		return fmt.Errorf("row has %d values, header has %d columns", len(row), len(header))
+	}
+	return nil
+}
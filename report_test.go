@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/truszkowski/launchdarkly-flags/launchdarkly"
+)
+
+// millisAgo is the API timestamp of d ago.
+func millisAgo(d time.Duration) int64 {
+	return time.Now().Add(-d).UnixMilli()
+}
+
+// newTestAPI serves listing as every flag listing page and statuses as
+// every flag status query.
+func newTestAPI(t *testing.T, listing, statuses string) *launchdarkly.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			fmt.Fprint(w, statuses)
+			return
+		}
+		fmt.Fprint(w, listing)
+	}))
+	t.Cleanup(server.Close)
+	return launchdarkly.NewClient("token", launchdarkly.WithHost(server.URL))
+}
+
+func TestCollectModifiedAnyEnv(t *testing.T) {
+	year := 365 * 24 * time.Hour
+	client := newTestAPI(t, fmt.Sprintf(`{"items":[{"key":"a","temporary":true,"creationDate":%d,"environments":{
+		"production":{"lastModified":%d},
+		"staging":{"lastModified":%d}
+	}}]}`, millisAgo(2*year), millisAgo(year), millisAgo(time.Hour)), `{"items":[]}`)
+
+	selection := selectionFlags{threshold: 180 * 24 * time.Hour, flagType: "temporary", sortBy: "maintainer", concurrency: 1}
+	connection := &connectionFlags{project: "default", env: "production"}
+
+	if flags := selection.collect(context.Background(), client, connection); len(flags) != 1 {
+		t.Fatalf("got %d flags unmodified in production, want 1", len(flags))
+	}
+
+	selection.modifiedAnyEnv = true
+	if flags := selection.collect(context.Background(), client, connection); len(flags) != 0 {
+		t.Fatalf("got %v modified an hour ago in staging with -modified-any-env, want none", flags)
+	}
+}
+
+func TestRowWriterCSV(t *testing.T) {
+	header := []string{"KEY", "TEAM", "DESCRIPTION"}
+	var buf bytes.Buffer
+	out := newRowWriter(&buf, "csv")
+	out.Write(header)
+	out.Write([]string{"a", "platform, growth", "spans\nlines"})
+	out.Flush()
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || len(rows[1]) != len(header) {
+		t.Fatalf("got %q, want a row of %d columns", rows, len(header))
+	}
+	if rows[1][1] != "platform, growth" {
+		t.Errorf("got %q, want the custom field values in one column", rows[1][1])
+	}
+}
+
+func TestMarkdownEscaper(t *testing.T) {
+	for value, want := range map[string]string{
+		"on | off":  `on \| off`,
+		`ends in \`: `ends in \\`,
+		"plain":     "plain",
+	} {
+		if got := markdownEscaper.Replace(value); got != want {
+			t.Errorf("markdownEscaper.Replace(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestCollectAllProjectsParallel(t *testing.T) {
+	var projects []string
+	for i := 0; i < 8; i++ {
+		projects = append(projects, fmt.Sprintf(`{"key":"project-%d"}`, i))
+	}
+	// inFlight and most count the listings served at the same time.
+	var inFlight, most atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			fmt.Fprint(w, `{"items":[]}`)
+		case r.URL.Path == "/api/v2/projects":
+			fmt.Fprintf(w, `{"items":[%s]}`, strings.Join(projects, ","))
+		default:
+			project := strings.TrimPrefix(r.URL.Path, "/api/v2/flags/")
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for m := most.Load(); n > m && !most.CompareAndSwap(m, n); m = most.Load() {
+			}
+			time.Sleep(10 * time.Millisecond)
+			fmt.Fprintf(w, `{"items":[{"key":"%s-flag","temporary":true,"creationDate":%d,"environments":{"production":{"lastModified":%d}}}]}`,
+				project, millisAgo(2*365*24*time.Hour), millisAgo(365*24*time.Hour))
+		}
+	}))
+	defer server.Close()
+	client := launchdarkly.NewClient("token", launchdarkly.WithHost(server.URL))
+
+	selection := selectionFlags{threshold: 180 * 24 * time.Hour, flagType: "temporary", sortBy: "maintainer", concurrency: 4, allProjects: true}
+	flags := selection.collect(context.Background(), client, &connectionFlags{env: "production"})
+
+	if len(flags) != len(projects) {
+		t.Fatalf("got %d flags, want one per project", len(flags))
+	}
+	seen := map[string]bool{}
+	for _, item := range flags {
+		if item.Key != item.Project+"-flag" || seen[item.Project] {
+			t.Errorf("got flag %s/%s, want a single flag of its own project", item.Project, item.Key)
+		}
+		seen[item.Project] = true
+	}
+	if most.Load() < 2 || most.Load() > 4 {
+		t.Errorf("got %d projects fetched at a time, want 2 to 4 with -concurrency 4", most.Load())
+	}
+}
+
+func TestReportColumns(t *testing.T) {
+	year := 365 * 24 * time.Hour
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			fmt.Fprint(w, `{"items":[]}`)
+		case r.URL.Path == "/api/v2/projects":
+			fmt.Fprint(w, `{"items":[{"key":"default"}]}`)
+		case strings.HasPrefix(r.URL.Path, "/api/v2/flags/"):
+			fmt.Fprintf(w, `{"items":[{"key":"a","description":"line one\nline, two","temporary":true,"creationDate":%d,
+				"customProperties":{"jira":{"name":"Jira","value":["ACME-1","ACME-2"]}},
+				"_lifecycle":{"stage":"live"},
+				"environments":{"production":{"lastModified":%d},"staging":{"lastModified":%d}}}]}`,
+				millisAgo(2*year), millisAgo(year), millisAgo(year))
+		default:
+			fmt.Fprint(w, `{"items":[]}`)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	annotations := filepath.Join(dir, "annotations.json")
+	if err := os.WriteFile(annotations, []byte(`{"a":"keep | until Q3"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "report.csv")
+
+	// A row not matching the header fails the run instead of writing it.
+	runReport([]string{"-host", server.URL, "-format", "csv", "-output", output, "-all-projects",
+		"-show-version", "-show-description", "-created-by", "-status-envs", "staging",
+		"-compare-envs", "production,staging", "-custom-field", "jira", "-watch-key", "a",
+		"-overdue-after", "1h", "-annotations-file", annotations})
+
+	file, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want the header and one flag", len(rows))
+	}
+	for _, column := range []string{"PROJECT", "VERSION", "DESCRIPTION", "CREATED BY", "LAST REQUESTED (staging)",
+		"STATUS (production)", "STATUS (staging)", "JIRA", "WATCH", "OVERDUE", "LIFECYCLE", "NOTE"} {
+		if columnIndex(rows[0], column) < 0 {
+			t.Errorf("header %q has no %s column", rows[0], column)
+		}
+	}
+}
+
+func TestReportMisconfigured(t *testing.T) {
+	year := 365 * 24 * time.Hour
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			fmt.Fprint(w, `{"items":[]}`)
+			return
+		}
+		fmt.Fprintf(w, `{"items":[
+			{"key":"stale","temporary":true,"creationDate":%d,"environments":{"production":{"lastModified":%d}}},
+			{"key":"new","temporary":true,"creationDate":%d,"environments":{}}
+		]}`, millisAgo(2*year), millisAgo(year), millisAgo(time.Hour))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	outputs := []string{filepath.Join(dir, "report.json"), filepath.Join(dir, "report.csv")}
+	runReport([]string{"-host", server.URL, "-format", "json,csv", "-output", strings.Join(outputs, ",")})
+
+	data, err := os.ReadFile(outputs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []FlagRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Misconfigured || !records[1].Misconfigured || records[1].Status != "misconfigured" {
+		t.Errorf("got %+v, want the stale flag followed by the misconfigured one", records)
+	}
+
+	file, err := os.Open(outputs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := columnIndex(rows[0], "STATUS")
+	if len(rows) != 3 || rows[2][0] != "new" || rows[2][status] != "misconfigured" {
+		t.Errorf("got %q, want the misconfigured flag in the last row", rows)
+	}
+}
+
+func TestCollectThresholdZero(t *testing.T) {
+	requested := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+	client := newTestAPI(t, fmt.Sprintf(`{"items":[{"key":"a","temporary":true,"creationDate":%d,"environments":{"production":{"lastModified":%d}}}]}`,
+		millisAgo(time.Hour), millisAgo(time.Hour)),
+		fmt.Sprintf(`{"items":[{"key":"a","environments":{"production":{"lastRequested":%q}}}]}`, requested))
+
+	selection := selectionFlags{threshold: 0, flagType: "temporary", sortBy: "maintainer", concurrency: 1}
+	flags := selection.collect(context.Background(), client, &connectionFlags{project: "default", env: "production"})
+	if len(flags) != 1 {
+		t.Fatalf("got %d flags created an hour ago with -threshold 0, want 1", len(flags))
+	}
+
+	threshold := selection.statusThreshold()
+	if status := flags[0].GetStatus(threshold); status != "inuse" {
+		t.Errorf("got status %s of a flag requested a day ago with -threshold 0, want inuse", status)
+	}
+	if summary := summarize(flags, threshold); summary.Inactive != 0 || summary.FlagDebt != 0 {
+		t.Errorf("got %d inactive and a flag debt of %f with -threshold 0, want none", summary.Inactive, summary.FlagDebt)
+	}
+}
+
+func TestCollectExplainFilter(t *testing.T) {
+	client := newTestAPI(t, fmt.Sprintf(`{"items":[{"key":"new","temporary":true,"creationDate":%d,"environments":{"production":{"lastModified":%d}}}]}`,
+		millisAgo(time.Hour), millisAgo(time.Hour)), `{"items":[]}`)
+
+	// Returns instead of exiting, for the caller to explain.
+	selection := selectionFlags{threshold: 180 * 24 * time.Hour, flagType: "temporary", sortBy: "maintainer", concurrency: 1, explain: true}
+	flags := selection.collect(context.Background(), client, &connectionFlags{project: "default", env: "production"})
+	if len(flags) != 1 {
+		t.Fatalf("got %d flags, want the excluded one too", len(flags))
+	}
+
+	var out strings.Builder
+	selection.explainFilter(&out, flags)
+	if !strings.Contains(out.String(), "excluded created within -threshold") {
+		t.Errorf("got explanation %q, want the flag excluded as created within -threshold", out.String())
+	}
+}
+
+func TestReportDiffCSV(t *testing.T) {
+	year := 365 * 24 * time.Hour
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			fmt.Fprint(w, `{"items":[]}`)
+			return
+		}
+		fmt.Fprintf(w, `{"items":[{"key":"stale","temporary":true,"creationDate":%d,"environments":{"production":{"lastModified":%d}}}]}`,
+			millisAgo(2*year), millisAgo(year))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	previous := filepath.Join(dir, "previous.json")
+	if err := os.WriteFile(previous, []byte(`[{"key":"cleaned, up","project":"default","maintainer":"unknown","status":"inactive"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	outputs := []string{filepath.Join(dir, "diff.csv"), filepath.Join(dir, "diff.ndjson")}
+	runReport([]string{"-host", server.URL, "-diff", previous, "-format", "csv,ndjson", "-output", strings.Join(outputs, ",")})
+
+	file, err := os.Open(outputs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"CHANGE", "KEY", "MAINTAINER", "STATUS", "LINK"},
+		{"new", "stale", "unknown", "unknown", server.URL + "/default/production/features/stale/targeting"},
+		{"removed", "cleaned, up", "unknown", "inactive", ""},
+	}
+	if fmt.Sprint(rows) != fmt.Sprint(want) {
+		t.Errorf("got csv %q, want %q", rows, want)
+	}
+
+	data, err := os.ReadFile(outputs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changes []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var change struct {
+			Change string `json:"change"`
+			Key    string `json:"key"`
+		}
+		if err := json.Unmarshal([]byte(line), &change); err != nil {
+			t.Fatal(err)
+		}
+		changes = append(changes, change.Change+" "+change.Key)
+	}
+	if fmt.Sprint(changes) != "[new stale removed cleaned, up]" {
+		t.Errorf("got ndjson changes %q, want the new and the removed flag", changes)
+	}
+}
+
+func TestCollectSortSeverityUnknownStatus(t *testing.T) {
+	year := 365 * 24 * time.Hour
+	item := func(key string) string {
+		return fmt.Sprintf(`{"key":%q,"temporary":true,"creationDate":%d,"environments":{"production":{"lastModified":%d}}}`, key, millisAgo(2*year), millisAgo(year))
+	}
+	requested := time.Now().Add(-year).UTC().Format(time.RFC3339)
+	client := newTestAPI(t, `{"items":[`+item("unknown")+","+item("requested")+","+item("never")+`]}`,
+		fmt.Sprintf(`{"items":[{"key":"requested","environments":{"production":{"lastRequested":%q}}},{"key":"never","environments":{"production":{}}}]}`, requested))
+
+	selection := selectionFlags{threshold: 180 * 24 * time.Hour, flagType: "temporary", sortBy: "severity", concurrency: 1}
+	var keys []string
+	for _, item := range selection.collect(context.Background(), client, &connectionFlags{project: "default", env: "production"}) {
+		keys = append(keys, item.Key)
+	}
+	if fmt.Sprint(keys) != "[never requested unknown]" {
+		t.Errorf("got %q, want never requested first and unknown status last", keys)
+	}
+}
+
+func TestInactiveForUnknownStatus(t *testing.T) {
+	item := launchdarkly.Flag{CreationDate: time.Now().Add(-48 * time.Hour), StatusUnknown: true}
+	if got := inactiveFor(item); got != "last requested unknown" {
+		t.Errorf("got %q of a flag without status, want last requested unknown", got)
+	}
+}
+
+func TestExplainFilterMisconfigured(t *testing.T) {
+	year := 365 * 24 * time.Hour
+	selection := selectionFlags{threshold: 180 * 24 * time.Hour, flagType: "temporary"}
+	flags := []launchdarkly.Flag{
+		{Key: "stale", Project: "default", Temporary: true, CreationDate: time.Now().Add(-2 * year), LastModified: time.Now().Add(-year)},
+		{Key: "new", Project: "default", Temporary: true, CreationDate: time.Now().Add(-time.Hour), LastModified: time.Now().Add(-time.Hour)},
+		{Key: "misconfigured", Project: "default", Temporary: true, Misconfigured: true},
+	}
+
+	var out strings.Builder
+	selection.explainFilter(&out, flags)
+	if !regexp.MustCompile(`misconfigured +separate +no data in -env, shown separately`).MatchString(out.String()) {
+		t.Errorf("got %q, want the misconfigured flag shown separately", out.String())
+	}
+	if !strings.HasSuffix(out.String(), "\n1 included, 1 excluded, 1 shown separately\n") {
+		t.Errorf("got %q, want the counts of every result last", out.String())
+	}
+}
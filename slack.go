@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+)
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackElement struct {
+	Type string     `json:"type"`
+	Text *slackText `json:"text,omitempty"`
+	URL  string     `json:"url,omitempty"`
+}
+
+type slackBlock struct {
+	Type      string        `json:"type"`
+	Text      *slackText    `json:"text,omitempty"`
+	Elements  []slackText   `json:"elements,omitempty"`
+	Accessory *slackElement `json:"accessory,omitempty"`
+}
+
+type SlackMessage struct {
+	Blocks []slackBlock `json:"blocks"`
+}
+
+// slackMaxBlocks is the most blocks Slack accepts in a single message.
+const slackMaxBlocks = 50
+
+// slackBlocks renders records as a Block Kit message: a section per
+// maintainer, in the order they first appear, with a context block of
+// counts, followed by a section per flag with a button linking to it. Flags
+// beyond Slack's block limit are left out, counted in a final context
+// block.
+func slackBlocks(title string, records []FlagRecord) SlackMessage {
+	var maintainers []string
+	byMaintainer := map[string][]FlagRecord{}
+	for _, record := range records {
+		if _, ok := byMaintainer[record.Maintainer]; !ok {
+			maintainers = append(maintainers, record.Maintainer)
+		}
+		byMaintainer[record.Maintainer] = append(byMaintainer[record.Maintainer], record)
+	}
+
+	message := SlackMessage{Blocks: []slackBlock{{
+		Type: "header",
+		Text: &slackText{Type: "plain_text", Text: title},
+	}}}
+
+	// The last block is kept for the count of flags left out.
+	limit := slackMaxBlocks - 1
+	shown := 0
+	for _, maintainer := range maintainers {
+		// A maintainer takes three blocks, worth it only with a flag.
+		if len(message.Blocks)+4 > limit {
+			break
+		}
+
+		group := byMaintainer[maintainer]
+		inactive := 0
+		for _, record := range group {
+			if record.Status == "inactive" {
+				inactive++
+			}
+		}
+
+		message.Blocks = append(message.Blocks,
+			slackBlock{Type: "divider"},
+			slackBlock{
+				Type: "section",
+				Text: &slackText{Type: "mrkdwn", Text: "*" + maintainer + "*"},
+			},
+			slackBlock{
+				Type: "context",
+				Elements: []slackText{{
+					Type: "mrkdwn",
+					Text: fmt.Sprintf("%d flags, %d inactive", len(group), inactive),
+				}},
+			},
+		)
+
+		for _, record := range group {
+			if len(message.Blocks) >= limit {
+				break
+			}
+			temporary := "permanent"
+			if record.Temporary {
+				temporary = "temporary"
+			}
+			block := slackBlock{
+				Type: "section",
+				Text: &slackText{Type: "mrkdwn", Text: fmt.Sprintf("`%s` %s, %s", record.Key, record.Status, temporary)},
+			}
+			if record.Link != "" {
+				block.Accessory = &slackElement{
+					Type: "button",
+					Text: &slackText{Type: "plain_text", Text: "Open"},
+					URL:  record.Link,
+				}
+			}
+			message.Blocks = append(message.Blocks, block)
+			shown++
+		}
+	}
+
+	if shown < len(records) {
+		message.Blocks = append(message.Blocks, slackBlock{
+			Type: "context",
+			Elements: []slackText{{
+				Type: "mrkdwn",
+				Text: fmt.Sprintf("+%d more flags, see the full report", len(records)-shown),
+			}},
+		})
+	}
+
+	return message
+}
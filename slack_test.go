@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSlackBlocks(t *testing.T) {
+	// Interleaved, as with -sort severity.
+	var records []FlagRecord
+	for i := 0; i < 60; i++ {
+		records = append(records, FlagRecord{Key: fmt.Sprintf("flag-%d", i), Maintainer: fmt.Sprintf("m%d@example.com", i%3), Status: "inactive"})
+	}
+
+	message := slackBlocks("Stale flags", records)
+	if len(message.Blocks) > slackMaxBlocks {
+		t.Fatalf("got %d blocks, Slack accepts at most %d", len(message.Blocks), slackMaxBlocks)
+	}
+
+	maintainers := map[string]int{}
+	shown := 0
+	for _, block := range message.Blocks {
+		if block.Type == "section" && block.Text != nil {
+			if strings.HasPrefix(block.Text.Text, "*") {
+				maintainers[block.Text.Text]++
+			} else {
+				shown++
+			}
+		}
+	}
+	for maintainer, count := range maintainers {
+		if count != 1 {
+			t.Errorf("got %d sections of %s, want one", count, maintainer)
+		}
+	}
+
+	last := message.Blocks[len(message.Blocks)-1]
+	if want := fmt.Sprintf("+%d more flags, see the full report", len(records)-shown); last.Type != "context" || last.Elements[0].Text != want {
+		t.Errorf("got last block %+v, want %q", last, want)
+	}
+
+	if message := slackBlocks("Stale flags", records[:3]); len(message.Blocks) != 1+3*3+3 {
+		t.Errorf("got %d blocks of 3 flags of 3 maintainers, want no count of flags left out", len(message.Blocks))
+	}
+}
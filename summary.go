@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/truszkowski/launchdarkly-flags/launchdarkly"
+)
+
+const month = 30 * 24 * time.Hour
+
+var ageBuckets = []struct {
+	label string
+	upTo  time.Duration
+}{
+	{"<1mo", month},
+	{"1-3mo", 3 * month},
+	{"3-6mo", 6 * month},
+	{"6-12mo", 12 * month},
+	{">12mo", 1<<63 - 1},
+}
+
+// printAgeHistogram writes an ASCII bar chart of flags bucketed by creation
+// age, scaled so that the largest bucket is 40 characters wide.
+func printAgeHistogram(w io.Writer, flags []launchdarkly.Flag) {
+	counts := make([]int, len(ageBuckets))
+	for _, item := range flags {
+		for i, bucket := range ageBuckets {
+			if item.Age() < bucket.upTo {
+				counts[i]++
+				break
+			}
+		}
+	}
+
+	largest := 0
+	for _, count := range counts {
+		largest = max(largest, count)
+	}
+
+	tb := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	for i, bucket := range ageBuckets {
+		width := 0
+		if largest > 0 {
+			width = (counts[i]*40 + largest - 1) / largest
+		}
+		fmt.Fprintf(tb, "%s\t%d\t%s\n", bucket.label, counts[i], strings.Repeat("#", width))
+	}
+	tb.Flush()
+}
+
+// Summary aggregates the reported flags.
+type Summary struct {
+	Flags    int `json:"flags"`
+	Inactive int `json:"inactive"`
+
+	// Unowned is the number of flags without a maintainer.
+	Unowned int `json:"unowned"`
+
+	// FlagDebt is the sum of days every flag has been inactive for beyond
+	// the threshold. Unlike the count it grows as flags keep rotting, so it
+	// shows whether debt is paid down when graphed over time.
+	FlagDebt float64 `json:"flagDebt"`
+
+	// Age and InactiveFor describe the distributions of creation age and of
+	// inactive time, in days.
+	Age         Distribution `json:"age"`
+	InactiveFor Distribution `json:"inactiveFor"`
+
+	// Requests are the API calls made up to the summary.
+	Requests launchdarkly.RequestStats `json:"requests"`
+}
+
+// Distribution summarizes skewed durations better than an average would.
+type Distribution struct {
+	Median float64 `json:"median"`
+	P90    float64 `json:"p90"`
+	Max    float64 `json:"max"`
+}
+
+// distribution of days, by the nearest rank.
+func distribution(days []float64) Distribution {
+	if len(days) == 0 {
+		return Distribution{}
+	}
+	sort.Float64s(days)
+	rank := func(p float64) float64 {
+		return days[int(math.Ceil(p*float64(len(days))))-1]
+	}
+	return Distribution{Median: rank(0.5), P90: rank(0.9), Max: days[len(days)-1]}
+}
+
+func summarize(flags []launchdarkly.Flag, threshold time.Duration) Summary {
+	summary := Summary{Flags: len(flags)}
+	var ages, inactive []float64
+	for _, item := range flags {
+		ages = append(ages, days(item.Age()))
+		if item.Inactive(threshold) {
+			summary.Inactive++
+		}
+		if item.MaintainerEmail == "unknown" {
+			summary.Unowned++
+		}
+
+		// Without a status there is no telling how long a flag is inactive,
+		// it is left out rather than taken as inactive since creation.
+		if item.StatusUnknown {
+			continue
+		}
+		inactive = append(inactive, days(item.InactiveFor()))
+		if over := item.InactiveFor() - threshold; over > 0 {
+			summary.FlagDebt += days(over)
+		}
+	}
+	summary.Age = distribution(ages)
+	summary.InactiveFor = distribution(inactive)
+	return summary
+}
+
+func (s Summary) Print(w io.Writer) {
+	fmt.Fprintf(w, "%d flags, %d inactive, flag debt %.0f days\n", s.Flags, s.Inactive, s.FlagDebt)
+	if s.Flags > 0 {
+		fmt.Fprintf(w, "age: median %.0f days, p90 %.0f, max %.0f\n", s.Age.Median, s.Age.P90, s.Age.Max)
+		fmt.Fprintf(w, "inactive: median %.0f days, p90 %.0f, max %.0f\n", s.InactiveFor.Median, s.InactiveFor.P90, s.InactiveFor.Max)
+	}
+	if s.Requests.Calls != nil {
+		fmt.Fprintf(w, "api: %s\n", s.Requests)
+	}
+	if s.Unowned > 0 {
+		fmt.Fprintf(w, "⚠ %d of %d stale flags have no maintainer\n", s.Unowned, s.Flags)
+	}
+}
+
+func (s Summary) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
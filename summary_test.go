@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/truszkowski/launchdarkly-flags/launchdarkly"
+)
+
+func TestSummarizeUnknownStatus(t *testing.T) {
+	now := time.Now()
+	year := 365 * 24 * time.Hour
+	flags := []launchdarkly.Flag{
+		{Key: "never", MaintainerEmail: "a@example.com", CreationDate: now.Add(-year), AsOf: now},
+		{Key: "unavailable", MaintainerEmail: "a@example.com", CreationDate: now.Add(-2 * year), AsOf: now, StatusUnknown: true, RequestsUnavailable: true},
+	}
+
+	summary := summarize(flags, 180*24*time.Hour)
+	if summary.Flags != 2 || summary.Inactive != 1 {
+		t.Errorf("got %d flags, %d inactive, want 2 and 1", summary.Flags, summary.Inactive)
+	}
+	if summary.FlagDebt != 185 {
+		t.Errorf("got a flag debt of %f days, want 185 of the never requested flag only", summary.FlagDebt)
+	}
+	if summary.InactiveFor.Max != 365 {
+		t.Errorf("got at most %f days inactive, want 365 without the flag of unknown status", summary.InactiveFor.Max)
+	}
+}
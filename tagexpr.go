@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// TagExpr is a boolean expression over flag tags, e.g.
+// "team:payments && !keep". It supports && (and), || (or), ! (not) and
+// parentheses; && binds tighter than ||. Any other run of characters is a
+// tag, matched exactly.
+type TagExpr interface {
+	Match(tags []string) bool
+}
+
+type tagTerm string
+
+func (t tagTerm) Match(tags []string) bool {
+	return slices.Contains(tags, string(t))
+}
+
+type tagNot struct{ expr TagExpr }
+
+func (t tagNot) Match(tags []string) bool {
+	return !t.expr.Match(tags)
+}
+
+type tagAnd struct{ left, right TagExpr }
+
+func (t tagAnd) Match(tags []string) bool {
+	return t.left.Match(tags) && t.right.Match(tags)
+}
+
+type tagOr struct{ left, right TagExpr }
+
+func (t tagOr) Match(tags []string) bool {
+	return t.left.Match(tags) || t.right.Match(tags)
+}
+
+// ParseTagExpr parses a tag expression, see TagExpr.
+func ParseTagExpr(s string) (TagExpr, error) {
+	p := &tagParser{tokens: tokenizeTagExpr(s)}
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in tag expression", p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+func tokenizeTagExpr(s string) []string {
+	var tokens []string
+	for i := 0; i < len(s); {
+		switch {
+		case unicode.IsSpace(rune(s[i])):
+			i++
+		case strings.HasPrefix(s[i:], "&&"), strings.HasPrefix(s[i:], "||"):
+			tokens = append(tokens, s[i:i+2])
+			i += 2
+		case strings.ContainsRune("!()", rune(s[i])):
+			tokens = append(tokens, s[i:i+1])
+			i++
+		default:
+			j := i
+			for j < len(s) && !unicode.IsSpace(rune(s[j])) && !strings.ContainsRune("!()&|", rune(s[j])) {
+				j++
+			}
+			if j == i {
+				// A lone & or |.
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		}
+	}
+	return tokens
+}
+
+type tagParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *tagParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *tagParser) or() (TagExpr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.pos++
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = tagOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *tagParser) and() (TagExpr, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.pos++
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = tagAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *tagParser) unary() (TagExpr, error) {
+	switch token := p.peek(); token {
+	case "":
+		return nil, fmt.Errorf("unexpected end of tag expression")
+	case "!":
+		p.pos++
+		expr, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return tagNot{expr}, nil
+	case "(":
+		p.pos++
+		expr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing ) in tag expression")
+		}
+		p.pos++
+		return expr, nil
+	case ")", "&&", "||", "&", "|":
+		return nil, fmt.Errorf("unexpected %q in tag expression", token)
+	default:
+		p.pos++
+		return tagTerm(token), nil
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// writeTOML writes records as a TOML array of tables called name. Fields are
+// named after their json tags, so the TOML output carries the same field set
+// as the JSON one; nil and omitempty-empty fields are left out, since TOML
+// has no null.
+func writeTOML(w io.Writer, name string, records []FlagRecord) {
+	for _, record := range records {
+		fmt.Fprintf(w, "[[%s]]\n", name)
+		writeTOMLFields(w, name, reflect.ValueOf(record))
+		fmt.Fprintln(w)
+	}
+}
+
+func writeTOMLFields(w io.Writer, table string, v reflect.Value) {
+	type subtable struct {
+		name  string
+		value reflect.Value
+	}
+	var subtables []subtable
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name, omitempty := jsonName(field)
+		if name == "" {
+			continue
+		}
+
+		value := v.Field(i)
+		if omitempty && value.IsZero() {
+			continue
+		}
+		if value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				continue
+			}
+			value = value.Elem()
+		}
+
+		switch {
+		case value.Kind() == reflect.Map:
+			keys := value.MapKeys()
+			sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+			for _, key := range keys {
+				subtables = append(subtables, subtable{table + "." + tomlKey(name) + "." + tomlKey(key.String()), value.MapIndex(key)})
+			}
+		case value.Kind() == reflect.Struct && value.Type() != reflect.TypeOf(time.Time{}):
+			subtables = append(subtables, subtable{table + "." + tomlKey(name), value})
+		default:
+			fmt.Fprintf(w, "%s = %s\n", tomlKey(name), tomlValue(value))
+		}
+	}
+
+	for _, sub := range subtables {
+		fmt.Fprintf(w, "[%s]\n", sub.name)
+		writeTOMLFields(w, sub.name, sub.value)
+	}
+}
+
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" || !field.IsExported() {
+		return "", false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, strings.Contains(options, "omitempty")
+}
+
+var tomlBareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func tomlKey(key string) string {
+	if tomlBareKey.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+func tomlValue(v reflect.Value) string {
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339Nano)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return tomlString(v.String())
+	case reflect.Slice, reflect.Array:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = tomlValue(v.Index(i))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// tomlString quotes s as a TOML basic string.
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
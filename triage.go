@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/truszkowski/launchdarkly-flags/launchdarkly"
+)
+
+// triageAction is what was chosen for a flag during triage.
+type triageAction struct {
+	flag       launchdarkly.Flag
+	archive    bool
+	maintainer launchdarkly.Member
+}
+
+// triage steps through flags asking on in what to do with each one, and
+// applies the chosen archivals and reassignments once confirmed.
+func triage(ctx context.Context, client *launchdarkly.Client, flags []launchdarkly.Flag, threshold time.Duration, comment string, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	ask := func(prompt string) (string, bool) {
+		fmt.Fprint(out, prompt)
+		if !scanner.Scan() {
+			return "", false
+		}
+		return strings.TrimSpace(scanner.Text()), true
+	}
+
+	var actions []triageAction
+	var members []launchdarkly.Member
+
+flags:
+	for i, item := range flags {
+		fmt.Fprintf(out, "\n[%d/%d] %s/%s\n", i+1, len(flags), item.Project, item.Key)
+		fmt.Fprintf(out, "  maintainer %s, created %s, last modified %s, last requested %s, %s\n",
+			item.MaintainerEmail, item.CreationDateAgo(), item.LastModifiedAgo(), item.LastRequestedAgo(), item.GetStatus(threshold))
+
+		for {
+			answer, ok := ask("[k]eep, [a]rchive, [r]eassign, [s]kip or [q]uit? ")
+			if !ok {
+				break flags
+			}
+
+			switch strings.ToLower(answer) {
+			case "k", "keep", "s", "skip":
+			case "a", "archive":
+				actions = append(actions, triageAction{flag: item, archive: true})
+			case "r", "reassign":
+				if members == nil {
+					var err error
+					if members, err = client.GetMembers(ctx); err != nil {
+						return fmt.Errorf("failed to get members: %w", err)
+					}
+				}
+				email, ok := ask("new maintainer email: ")
+				if !ok {
+					break flags
+				}
+				member, found := findMember(members, email)
+				if !found {
+					fmt.Fprintf(out, "no member %s\n", email)
+					continue
+				}
+				actions = append(actions, triageAction{flag: item, maintainer: member})
+			case "q", "quit":
+				break flags
+			default:
+				continue
+			}
+			break
+		}
+	}
+
+	if len(actions) == 0 {
+		fmt.Fprintln(out, "\nnothing to do")
+		return nil
+	}
+
+	fmt.Fprintln(out)
+	for _, action := range actions {
+		if action.archive {
+			fmt.Fprintf(out, "archive %s/%s\n", action.flag.Project, action.flag.Key)
+		} else {
+			fmt.Fprintf(out, "reassign %s/%s to %s\n", action.flag.Project, action.flag.Key, action.maintainer.Email)
+		}
+	}
+	if answer, _ := ask(fmt.Sprintf("apply %d changes? [y/N] ", len(actions))); !strings.EqualFold(answer, "y") {
+		fmt.Fprintln(out, "nothing changed")
+		return nil
+	}
+
+	for _, action := range actions {
+		if action.archive {
+			if err := client.ArchiveFlag(ctx, action.flag.Project, action.flag.Key, comment); err != nil {
+				return fmt.Errorf("failed to archive %s: %w", action.flag.Key, err)
+			}
+			fmt.Fprintf(out, "archived %s/%s\n", action.flag.Project, action.flag.Key)
+			continue
+		}
+		if err := client.SetMaintainer(ctx, action.flag.Project, action.flag.Key, action.maintainer.ID, comment); err != nil {
+			return fmt.Errorf("failed to reassign %s: %w", action.flag.Key, err)
+		}
+		fmt.Fprintf(out, "reassigned %s/%s to %s\n", action.flag.Project, action.flag.Key, action.maintainer.Email)
+	}
+
+	return nil
+}
+
+func findMember(members []launchdarkly.Member, email string) (launchdarkly.Member, bool) {
+	for _, member := range members {
+		if strings.EqualFold(member.Email, email) {
+			return member, true
+		}
+	}
+	return launchdarkly.Member{}, false
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// warningsAsErrors is set by -warnings-as-errors.
+var warningsAsErrors bool
+
+// warnings collects the warnings of the run, printed together at its end so
+// they aren't scattered through progress output. Fetching warns from several
+// goroutines at once.
+var warnings struct {
+	sync.Mutex
+	pending []string
+	count   int
+}
+
+// warnf records a warning for the end of the run.
+func warnf(format string, args ...interface{}) {
+	warnings.Lock()
+	defer warnings.Unlock()
+	warnings.pending = append(warnings.pending, fmt.Sprintf(format, args...))
+	warnings.count++
+}
+
+// printWarnings writes the warnings recorded since the last call to stderr,
+// before the run ends or exits early.
+func printWarnings() {
+	warnings.Lock()
+	defer warnings.Unlock()
+	for _, warning := range warnings.pending {
+		fmt.Fprintln(os.Stderr, "warning:", warning)
+	}
+	warnings.pending = nil
+}
+
+// finishWarnings prints the remaining warnings and, with
+// -warnings-as-errors, exits with exitWarnings if there were any.
+func finishWarnings() {
+	printWarnings()
+	if warningsAsErrors && warnings.count > 0 {
+		fmt.Fprintf(os.Stderr, "error: %d warnings with -warnings-as-errors\n", warnings.count)
+		os.Exit(exitWarnings)
+	}
+}
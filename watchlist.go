@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/truszkowski/launchdarkly-flags/launchdarkly"
+)
+
+// Watchlist holds flag keys, or project/key pairs, reported whether they are
+// stale or not.
+type Watchlist map[string]bool
+
+// loadWatchlist reads a file with a key per line, ignoring empty lines and
+// # comments.
+func loadWatchlist(path string, watchlist Watchlist) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if key := strings.TrimSpace(line); key != "" {
+			watchlist[key] = true
+		}
+	}
+	return scanner.Err()
+}
+
+func (w Watchlist) Watched(f launchdarkly.Flag) bool {
+	return w[f.Project+"/"+f.Key] || w[f.Key]
+}